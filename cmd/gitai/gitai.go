@@ -7,7 +7,6 @@ import (
 	"github.com/ozankasikci/gitai/internal/cmd"
 	"github.com/ozankasikci/gitai/internal/config"
 	"github.com/ozankasikci/gitai/internal/logger"
-	"github.com/pterm/pterm"
 )
 
 var osExit = os.Exit
@@ -30,8 +29,11 @@ func main() {
 	// Check if we're running config setup command
 	isConfigSetup := len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "setup"
 
+	// Hook commands run from git and must never prompt or print the banner
+	isHook := cmd.IsHookCommand(os.Args[1:])
+
 	// Only run config setup if config doesn't exist and we're not explicitly running setup
-	if !isConfigSetup {
+	if !isConfigSetup && !isHook {
 		cfg := config.Get()
 		if !cfg.IsSetupDone() {
 			if err := config.Setup(); err != nil {
@@ -40,21 +42,7 @@ func main() {
 			}
 		}
 
-		// Get config and provider/model info for display
-		cfg = config.Get()
-		provider, model := cfg.GetProviderAndModel()
-
-		// Create table data
-		tableData := pterm.TableData{
-			{"Provider", provider},
-			{"Model", model},
-		}
-
-		// Render table
-		_ = pterm.DefaultTable.
-			WithData(tableData).
-			WithBoxed(true).
-			Render()
+		cmd.PrintBanner()
 	}
 
 	// Execute root command
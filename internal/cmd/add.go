@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/spf13/cobra"
 	"github.com/ozankasikci/gitai/internal/git"
 	"github.com/charmbracelet/lipgloss"
@@ -11,9 +13,38 @@ import (
 )
 
 type fileSelection struct {
-	Path     string
-	Status   string
-	IsStaged bool
+	Path        string
+	DisplayPath string
+	Status      string
+	// ShortStatus holds the index and worktree columns of git status -s
+	ShortStatus string
+	IsStaged    bool
+	// Partial is set when the file is staged but its worktree still differs,
+	// because only some hunks were staged or it was edited afterwards
+	Partial bool
+	// OldPath is the source of a staged rename or copy
+	OldPath string
+}
+
+func newFileSelection(change git.FileChange) fileSelection {
+	return fileSelection{
+		Path:        change.Path,
+		OldPath:     change.OldPath,
+		DisplayPath: change.DisplayPath(),
+		Status:      change.Status,
+		ShortStatus: change.ShortStatus(),
+		IsStaged:    change.Staged,
+		Partial:     change.Staged && change.HasUnstagedChanges(),
+	}
+}
+
+// paths returns the index paths staging the file touches: both sides of a
+// rename, or the file's own path
+func (f fileSelection) paths() []string {
+	if f.Status == "renamed" {
+		return []string{f.OldPath, f.Path}
+	}
+	return []string{f.Path}
 }
 
 type model struct {
@@ -23,6 +54,28 @@ type model struct {
 	spinner  spinner.Model
 	loading  bool
 	quitting bool
+	// hunks is the drill-in view of hunkFile, if open
+	hunks    *hunkModel
+	hunkFile int
+	status   string
+	// preview shows the diff of the file under the cursor
+	preview     viewport.Model
+	previewPath string
+	// collapsed holds the directories whose files are hidden
+	collapsed map[string]bool
+	// filter is the fuzzy filter typed after '/'; filtering is set while it
+	// is being edited
+	filter    string
+	filtering bool
+	// offset is the first visible row and height the terminal height
+	offset int
+	height int
+	// errs holds the last staging error of each file by path
+	errs map[string]error
+	// confirm is the action waiting for confirmation, if any
+	confirm *addConfirm
+	// groups are the commits suggested for the changed files
+	groups []addGroup
 }
 
 type toggleCompleteMsg struct{}
@@ -34,19 +87,131 @@ func initialModel(changes []git.FileChange) model {
 
 	selections := make([]fileSelection, len(changes))
 	for i, change := range changes {
-		selections[i] = fileSelection{
-			Path:     change.Path,
-			Status:   change.Status,
-			IsStaged: change.Staged,
+		selections[i] = newFileSelection(change)
+	}
+
+	m := model{
+		choices:   selections,
+		selected:  make(map[int]bool),
+		spinner:   s,
+		loading:   false,
+		preview:   newPreview(),
+		collapsed: make(map[string]bool),
+		errs:      make(map[string]error),
+	}
+	for i, choice := range selections {
+		m.selected[i] = choice.IsStaged
+	}
+	m.refreshPreview()
+	return m
+}
+
+// refreshStatus reloads the changed files from git after the index changed.
+// Unstaging a rename lists its two sides separately again, and files that no
+// longer differ from HEAD drop out.
+func (m *model) refreshStatus() {
+	changes, err := git.GetAllChanges()
+	if err != nil {
+		m.status = fmt.Sprintf("Failed to refresh status: %v", err)
+		return
+	}
+
+	// Keep the cursor on the same file or directory
+	var current string
+	if row, ok := m.currentRow(); ok {
+		current = row.dir
+		if !row.isDir() {
+			current = m.choices[row.file].Path
+		}
+	}
+
+	m.choices = make([]fileSelection, len(changes))
+	m.selected = make(map[int]bool)
+	for i, change := range changes {
+		m.choices[i] = newFileSelection(change)
+		m.selected[i] = m.choices[i].IsStaged
+	}
+
+	for i, row := range m.rows() {
+		if row.dir == current || (!row.isDir() && m.choices[row.file].Path == current) {
+			m.cursor = i
+			break
+		}
+	}
+	m.cursor = max(min(m.cursor, len(m.rows())-1), 0)
+}
+
+// currentRow returns the row under the cursor
+func (m model) currentRow() (addRow, bool) {
+	rows := m.rows()
+	if m.cursor >= len(rows) {
+		return addRow{}, false
+	}
+	return rows[m.cursor], true
+}
+
+// refreshPreview renders the diff of the file under the cursor, keeping the
+// scroll position while the cursor stays on the same file
+func (m *model) refreshPreview() {
+	row, ok := m.currentRow()
+	if !ok {
+		m.preview.SetContent(hunkDimStyle.Render("No matching files"))
+		m.previewPath = ""
+		return
+	}
+
+	key := row.dir + "/"
+	if row.isDir() {
+		var s strings.Builder
+		s.WriteString(hunkHeaderStyle.Bold(true).Render(row.dir+"/") + "\n")
+		for _, i := range m.rowFiles(row) {
+			s.WriteString(fmt.Sprintf("%s (%s)\n", m.choices[i].DisplayPath, m.choices[i].Status))
 		}
+		m.preview.SetContent(s.String())
+	} else {
+		key = m.choices[row.file].Path
+		m.preview.SetContent(renderFilePreview(m.choices[row.file]))
 	}
+	if key != m.previewPath {
+		m.preview.GotoTop()
+		m.previewPath = key
+	}
+}
+
+// setStaged stages or unstages the whole file at index i, recording any
+// error to show next to it
+func (m *model) setStaged(i int, staged bool) {
+	choice := m.choices[i]
+	delete(m.errs, choice.Path)
+	for _, path := range choice.paths() {
+		var err error
+		if staged {
+			err = git.StageFile(path)
+		} else {
+			err = git.RestoreStaged(path)
+		}
+		if err != nil {
+			logger.Errorf("Failed to update %s: %v", path, err)
+			m.errs[choice.Path] = err
+			return
+		}
+	}
+}
 
-	return model{
-		choices:  selections,
-		selected: make(map[int]bool),
-		spinner:  s,
-		loading:  false,
+// toggleFiles stages the given files unless all of them are staged already,
+// in which case they are unstaged
+func (m *model) toggleFiles(files []int) {
+	allStaged := true
+	for _, i := range files {
+		if !m.choices[i].IsStaged || m.choices[i].Partial {
+			allStaged = false
+			break
+		}
 	}
+	for _, i := range files {
+		m.setStaged(i, !allStaged)
+	}
+	m.refreshStatus()
 }
 
 func (m model) Init() tea.Cmd {
@@ -60,11 +225,54 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case tea.WindowSizeMsg:
+		resizePreview(&m.preview, msg.Width, msg.Height)
+		m.height = msg.Height
+		m.scrollToCursor()
+		return m, nil
+
 	case toggleCompleteMsg:
 		m.loading = false
 		return m, nil
 
+	case groupsMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.groups = msg.groups
+		if len(m.groups) == 0 {
+			m.status = "The AI did not suggest any groups"
+		}
+		m.scrollToCursor()
+		return m, nil
+
+	case stashDoneMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Stashed %d file(s): %s", msg.count, msg.message)
+		m.refreshStatus()
+		m.scrollToCursor()
+		m.refreshPreview()
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.hunks != nil {
+			if msg.String() == "ctrl+c" {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			cmd := m.hunks.Update(msg)
+			if m.hunks.done {
+				m.closeHunks()
+				m.refreshPreview()
+			}
+			return m, cmd
+		}
 		if m.loading {
 			if msg.String() == "q" || msg.String() == "ctrl+c" {
 				m.quitting = true
@@ -72,6 +280,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.confirm != nil {
+			confirm := m.confirm
+			m.confirm = nil
+			if msg.String() == "y" {
+				m.discard(confirm.paths)
+				m.scrollToCursor()
+				m.refreshPreview()
+			}
+			return m, nil
+		}
+
+		rows := m.rows()
+		row, hasRow := m.currentRow()
+		m.status = ""
 
 		switch msg.String() {
 		case "ctrl+c", "q":
@@ -82,42 +307,91 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor--
 			}
 		case "down", "j":
-			if m.cursor < len(m.choices)-1 {
+			if m.cursor < len(rows)-1 {
 				m.cursor++
 			}
+		case "home", "g":
+			m.cursor = 0
+		case "end", "G":
+			m.cursor = max(len(rows)-1, 0)
+		case "pgdown", "ctrl+d":
+			m.preview.HalfViewDown()
+			return m, nil
+		case "pgup", "ctrl+u":
+			m.preview.HalfViewUp()
+			return m, nil
+		case "/":
+			m.filtering = true
+			return m, nil
+		case "esc":
+			m.filter = ""
+			m.cursor = 0
 		case "a":
-			// Toggle all files
-			allStaged := true
-			for _, choice := range m.choices {
-				if !choice.IsStaged {
-					allStaged = false
-					break
+			// Toggle all files, or only the matching ones while filtering
+			files := make([]int, len(m.choices))
+			for i := range files {
+				files[i] = i
+			}
+			if m.filter != "" {
+				files = files[:0]
+				for _, r := range rows {
+					files = append(files, r.file)
 				}
 			}
-
-			for i := range m.choices {
-				if allStaged {
-					git.RestoreStaged(m.choices[i].Path)
-					m.choices[i].IsStaged = false
-					m.selected[i] = false
-				} else {
-					git.StageFile(m.choices[i].Path)
-					m.choices[i].IsStaged = true
-					m.selected[i] = true
+			m.toggleFiles(files)
+		case "right", "l":
+			if !hasRow {
+				break
+			}
+			if row.isDir() {
+				m.collapsed[row.dir] = false
+				break
+			}
+			hunks, err := newHunkModel(m.choices[row.file].Path)
+			if err != nil {
+				m.status = err.Error()
+				return m, nil
+			}
+			m.status = ""
+			m.hunks = hunks
+			m.hunkFile = row.file
+			return m, nil
+		case "left", "h":
+			if !hasRow || m.filter != "" {
+				break
+			}
+			if row.isDir() && !m.collapsed[row.dir] {
+				m.collapsed[row.dir] = true
+				break
+			}
+			// Move to the parent directory
+			for i := m.cursor - 1; i >= 0; i-- {
+				if rows[i].isDir() && rows[i].depth < row.depth {
+					m.cursor = i
+					break
 				}
 			}
 		case " ":
-			currentFile := m.choices[m.cursor].Path
-			if m.choices[m.cursor].IsStaged {
-				git.RestoreStaged(currentFile)
-				m.choices[m.cursor].IsStaged = false
-				m.selected[m.cursor] = false
-			} else {
-				git.StageFile(currentFile)
-				m.choices[m.cursor].IsStaged = true
-				m.selected[m.cursor] = true
+			if hasRow {
+				m.toggleFiles(m.rowFiles(row))
 			}
+		case "d":
+			m.confirmDiscard()
 			return m, nil
+		case "u":
+			m.undoDiscard()
+		case "s":
+			if cmd := m.stash(); cmd != nil {
+				m.loading = true
+				return m, cmd
+			}
+		case "c":
+			if len(m.choices) > 0 {
+				m.loading = true
+				return m, m.suggestGroups()
+			}
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			m.toggleGroup(int(msg.String()[0] - '1'))
 		case "enter":
 			logger.Infof("Proceeding to add the selected files")
 			for i, choice := range m.choices {
@@ -126,45 +400,141 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.quitting = false
 			return m, tea.Quit
 		}
+		m.cursor = max(min(m.cursor, len(m.rows())-1), 0)
+		m.scrollToCursor()
+		m.refreshPreview()
 	}
 	return m, nil
 }
 
+// updateFilter edits the fuzzy filter; enter keeps it and esc clears it
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+	case tea.KeyBackspace:
+		if m.filter != "" {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	default:
+		return m, nil
+	}
+
+	m.cursor = 0
+	m.scrollToCursor()
+	m.refreshPreview()
+	return m, nil
+}
+
+// closeHunks returns from the hunk view to the file list
+func (m *model) closeHunks() {
+	m.hunks = nil
+	m.refreshStatus()
+}
+
 func (m model) View() string {
 	if m.loading {
 		return fmt.Sprintf("%s Processing...\n", m.spinner.View())
 	}
+	if m.hunks != nil {
+		return m.hunks.View()
+	}
 
-	s := "Use space to stage/unstage, 'a' to toggle all, enter to finish\n\n"
+	s := "Use space to stage/unstage, → to expand or stage hunks, ← to collapse, / to filter, 'a' to toggle all, pgup/pgdown to scroll the diff, enter to finish\n"
+	switch {
+	case m.filtering:
+		s += fmt.Sprintf("Filter: %s█\n", m.filter)
+	case m.filter != "":
+		s += fmt.Sprintf("Filter: %s (esc to clear)\n", m.filter)
+	default:
+		s += "\n"
+	}
 
-	for i, choice := range m.choices {
+	rows := m.rows()
+	end := len(rows)
+	if height := m.listHeight(); height > 0 {
+		end = min(m.offset+height, len(rows))
+	}
+	for i := m.offset; i < end; i++ {
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
 		}
+		s += fmt.Sprintf("%s %s\n", cursor, m.rowLabel(rows[i]))
+	}
+	if len(rows) == 0 {
+		s += "  No matching files\n"
+	}
+	if end-m.offset < len(rows) {
+		s += hunkDimStyle.Render(fmt.Sprintf("  %d-%d of %d", m.offset+1, end, len(rows))) + "\n"
+	}
 
-		checked := " "
-		if choice.IsStaged {
-			checked = "x"
-		}
+	s += m.groupsView()
 
-		s += fmt.Sprintf("%s [%s] %s (%s)\n", cursor, checked, choice.Path, choice.Status)
+	if m.confirm != nil {
+		s += "\n" + hunkErrorStyle.Render(m.confirm.prompt) + "\n"
+	} else if m.status != "" {
+		s += "\n" + m.status + "\n"
 	}
 
-	s += "\n(press q to quit)\n"
+	s += "\n" + previewBorderStyle.Width(m.preview.Width).Render(m.preview.View()) + "\n"
+	s += "d discard • u undo discard • s stash • c suggest groups • (press q to quit)\n"
 	return s
 }
 
+// addFlags holds the non-interactive staging flags
+var addFlags struct {
+	all    bool
+	update bool
+	// Only registered on the add command
+	dryRun bool
+}
+
 func NewAddCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "add",
+		Use:   "add [pathspec...]",
 		Short: "Interactively stage files for commit",
-		RunE:  runAdd,
+		Long: `Without arguments, open an interactive view to stage files, hunks and lines.
+
+With pathspecs, --all or --update, stage matching changes directly without
+any prompt, which works in scripts. Pathspecs are relative to the current
+directory; '*', '?' and '[...]' match within a path component, '**' matches
+across directories, and a directory matches everything below it.`,
+		Example: `  gitai add 'internal/**/*.go'
+  gitai add --update
+  gitai add --all --dry-run`,
+		RunE: runAdd,
 	}
+	addStagingFlags(cmd)
+	cmd.Flags().BoolVarP(&addFlags.dryRun, "dry-run", "n", false, "Only show what would be staged")
 	return cmd
 }
 
+func addStagingFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&addFlags.all, "all", "A", false, "Stage all changes, including untracked files")
+	cmd.Flags().BoolVarP(&addFlags.update, "update", "u", false, "Stage changes to tracked files only")
+}
+
 func runAdd(cmd *cobra.Command, args []string) error {
+	if addFlags.all && addFlags.update {
+		return fmt.Errorf("--all and --update cannot be used together")
+	}
+	if len(args) > 0 || addFlags.all || addFlags.update {
+		return stageChanges(args)
+	}
+	if addFlags.dryRun {
+		return fmt.Errorf("--dry-run needs pathspecs, --all or --update")
+	}
+
 	changes, err := git.GetAllChanges()
 	if err != nil {
 		return fmt.Errorf("failed to get changes: %w", err)
@@ -175,6 +545,10 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if !isInteractive() {
+		return fmt.Errorf("not running in a terminal; pass pathspecs, --all or --update to stage without prompting")
+	}
+
 	p := tea.NewProgram(initialModel(changes))
 	m, err := p.Run()
 	if err != nil {
@@ -191,15 +565,58 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		// Add this section to actually stage the files
 		logger.Infof("Proceeding to add the selected files")
 		for idx, selected := range finalModel.selected {
-			if selected {
-				path := finalModel.choices[idx].Path
-				if err := git.StageFile(path); err != nil {
-					return fmt.Errorf("failed to stage file %s: %w", path, err)
+			// Partially staged files already have the chosen hunks in the index
+			if selected && !finalModel.choices[idx].Partial {
+				for _, path := range finalModel.choices[idx].paths() {
+					if err := git.StageFile(path); err != nil {
+						return fmt.Errorf("failed to stage file %s: %w", path, err)
+					}
+					logger.Debugf("Staged file: %s", path)
 				}
-				logger.Debugf("Staged file: %s", path)
 			}
 		}
 	}
 
 	return nil
-} 
\ No newline at end of file
+} 
+
+// stageChanges stages the unstaged changes matching pathspecs, or all of
+// them, without prompting
+func stageChanges(pathspecs []string) error {
+	changes, err := git.GetAllChanges()
+	if err != nil {
+		return fmt.Errorf("failed to get changes: %w", err)
+	}
+	if len(pathspecs) > 0 {
+		if changes, err = git.SelectChanges(changes, pathspecs); err != nil {
+			return err
+		}
+	}
+
+	var staged int
+	for _, change := range changes {
+		if !change.HasUnstagedChanges() || (addFlags.update && change.Worktree == '?') {
+			continue
+		}
+
+		if addFlags.dryRun {
+			action := "add"
+			if change.Worktree == 'D' {
+				action = "remove"
+			}
+			fmt.Printf("%s '%s'\n", action, change.Path)
+			continue
+		}
+
+		if err := git.StageFile(change.Path); err != nil {
+			return fmt.Errorf("failed to stage file %s: %w", change.Path, err)
+		}
+		logger.Debugf("Staged file: %s", change.Path)
+		staged++
+	}
+
+	if staged > 0 {
+		logger.Infof("Staged %d file(s)", staged)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ozankasikci/gitai/internal/git"
+	"github.com/ozankasikci/gitai/internal/llm"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// addConfirm is a pending action waiting for y/n in the add view
+type addConfirm struct {
+	prompt string
+	paths  []string
+}
+
+type stashDoneMsg struct {
+	message string
+	count   int
+	err     error
+}
+
+// rowPaths returns the index paths of the files under the cursor
+func (m model) rowPaths() []string {
+	row, ok := m.currentRow()
+	if !ok {
+		return nil
+	}
+	var paths []string
+	for _, i := range m.rowFiles(row) {
+		paths = append(paths, m.choices[i].paths()...)
+	}
+	return paths
+}
+
+// confirmDiscard asks before discarding the worktree changes of the files
+// under the cursor
+func (m *model) confirmDiscard() {
+	paths := m.rowPaths()
+	if len(paths) == 0 {
+		return
+	}
+	m.confirm = &addConfirm{
+		prompt: fmt.Sprintf("Discard worktree changes to %d file(s)? Untracked files are deleted; 'u' undoes this. [y/N]", len(paths)),
+		paths:  paths,
+	}
+}
+
+// discard runs a confirmed discard
+func (m *model) discard(paths []string) {
+	if err := git.DiscardChanges(paths); err != nil {
+		m.status = fmt.Sprintf("Failed to discard changes: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Discarded changes to %d file(s), press 'u' to undo", len(paths))
+	m.refreshStatus()
+}
+
+// undoDiscard brings back the most recently discarded changes
+func (m *model) undoDiscard() {
+	paths, err := git.UndoDiscard()
+	if err != nil {
+		m.status = fmt.Sprintf("Failed to undo: %v", err)
+		return
+	}
+	m.status = fmt.Sprintf("Restored %d file(s)", len(paths))
+	m.refreshStatus()
+}
+
+// stash stashes the files under the cursor with a generated message
+func (m model) stash() tea.Cmd {
+	row, ok := m.currentRow()
+	if !ok {
+		return nil
+	}
+	var choices []fileSelection
+	var paths []string
+	for _, i := range m.rowFiles(row) {
+		choices = append(choices, m.choices[i])
+		paths = append(paths, m.choices[i].paths()...)
+	}
+
+	return func() tea.Msg {
+		message := stashMessage(choices)
+		err := git.StashFiles(paths, message)
+		return stashDoneMsg{message: message, count: len(choices), err: err}
+	}
+}
+
+// stashMessage asks the provider to summarize the stashed changes, falling
+// back to a plain description when that fails
+func stashMessage(choices []fileSelection) string {
+	fallback := fmt.Sprintf("gitai: %d file(s) stashed from gitai add", len(choices))
+
+	opts, err := contentOptions()
+	if err != nil {
+		logger.Errorf("Failed to prepare stash message: %v", err)
+		return fallback
+	}
+	client, err := llm.NewLLMClient()
+	if err != nil {
+		logger.Errorf("Failed to create LLM client: %v", err)
+		return fallback
+	}
+
+	content, err := describeFiles(choices, opts)
+	if err != nil {
+		logger.Errorf("Failed to prepare stash message: %v", err)
+		return fallback
+	}
+	suggestions, err := client.GenerateCommitSuggestions(content)
+	if err != nil || len(suggestions) == 0 {
+		logger.Errorf("Failed to generate stash message: %v", err)
+		return fallback
+	}
+	return firstLine(suggestions[0].Message)
+}
+
+// omittedContent replaces the diff of files matched by the ignore files
+const omittedContent = "(changed, content omitted)\n"
+
+// describeFiles renders the staged and unstaged diffs of files for a
+// prompt, leaving out the content of ignored files
+func describeFiles(choices []fileSelection, opts git.ContentOptions) (string, error) {
+	ignored, err := ignoredFiles(choices)
+	if err != nil {
+		return "", err
+	}
+
+	var content strings.Builder
+	for _, choice := range choices {
+		diff := omittedContent
+		if !ignored[choice.Path] {
+			diff = fileDiff(choice, opts)
+		}
+		content.WriteString(fmt.Sprintf("=== %s (%s, %s) ===\n%s\n", choice.DisplayPath, choice.ShortStatus, choice.Status, diff))
+	}
+	return content.String(), nil
+}
+
+// ignoredFiles returns the paths of the files whose content must not be
+// sent to the provider. A renamed file is ignored if either name is.
+func ignoredFiles(choices []fileSelection) (map[string]bool, error) {
+	var paths []string
+	for _, choice := range choices {
+		paths = append(paths, choice.paths()...)
+	}
+	matched, err := git.IgnoredPaths(paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ignore files: %w", err)
+	}
+
+	ignored := make(map[string]bool)
+	for _, choice := range choices {
+		for _, path := range choice.paths() {
+			if matched[path] {
+				ignored[choice.Path] = true
+			}
+		}
+	}
+	return ignored, nil
+}
+
+// fileDiff returns the staged and unstaged hunks of a file as prompt text,
+// honoring the size limit and secret redaction
+func fileDiff(choice fileSelection, opts git.ContentOptions) string {
+	maxSize := opts.MaxFileSize
+	if maxSize <= 0 {
+		maxSize = git.DefaultMaxFileSize
+	}
+
+	var diff strings.Builder
+	for _, get := range []func(string) ([]git.Hunk, error){git.GetStagedHunks, git.GetUnstagedHunks} {
+		hunks, err := get(choice.Path)
+		if errors.Is(err, git.ErrNotText) {
+			continue
+		}
+		if err != nil {
+			logger.Debugf("Failed to diff %s: %v", choice.Path, err)
+			continue
+		}
+		for _, hunk := range hunks {
+			diff.WriteString(hunk.String())
+		}
+	}
+
+	switch {
+	case diff.Len() == 0:
+		return "(no text diff)\n"
+	case int64(diff.Len()) > maxSize:
+		return "(diff too large, omitted)\n"
+	case opts.Redactor != nil:
+		return opts.Redactor.Redact(choice.Path, diff.String())
+	default:
+		return diff.String()
+	}
+}
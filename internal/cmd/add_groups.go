@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ozankasikci/gitai/internal/llm"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// groupColors label the proposed groups; groups past the palette reuse it
+var groupColors = []lipgloss.Color{"205", "39", "42", "214", "141", "203", "51", "226", "99"}
+
+// maxGroups is how many groups can be staged with the number keys
+const maxGroups = 9
+
+// addGroup is a proposed commit of whole files
+type addGroup struct {
+	message string
+	paths   []string
+}
+
+type groupsMsg struct {
+	groups []addGroup
+	err    error
+}
+
+func groupStyle(group int) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(groupColors[group%len(groupColors)]).Bold(true)
+}
+
+// suggestGroups asks the provider to cluster the changed files into commits,
+// numbering each file as a unit with its staged and unstaged diff
+func (m model) suggestGroups() tea.Cmd {
+	choices := append([]fileSelection(nil), m.choices...)
+	return func() tea.Msg {
+		opts, err := contentOptions()
+		if err != nil {
+			return groupsMsg{err: err}
+		}
+
+		ignored, err := ignoredFiles(choices)
+		if err != nil {
+			return groupsMsg{err: err}
+		}
+
+		var units strings.Builder
+		units.WriteString("=== Changed Files ===\n")
+		for i, choice := range choices {
+			diff := omittedContent
+			if !ignored[choice.Path] {
+				diff = fileDiff(choice, opts)
+			}
+			units.WriteString(fmt.Sprintf("\n=== Unit %d: %s (%s, %s) ===\n%s", i+1, choice.DisplayPath, choice.ShortStatus, choice.Status, diff))
+		}
+		logger.Debugf("\n=== Files to group ===\n%s\n", units.String())
+
+		client, err := llm.NewLLMClient()
+		if err != nil {
+			return groupsMsg{err: fmt.Errorf("failed to create LLM client: %w", err)}
+		}
+		proposed, err := client.GenerateCommitGroups(units.String())
+		if err != nil {
+			return groupsMsg{err: fmt.Errorf("failed to generate groups: %w", err)}
+		}
+		return groupsMsg{groups: fileGroups(proposed, choices)}
+	}
+}
+
+// fileGroups maps the units of the proposed groups back to files, dropping
+// unknown and repeated units, empty groups and groups past maxGroups
+func fileGroups(proposed []llm.CommitGroup, choices []fileSelection) []addGroup {
+	claimed := make(map[int]bool)
+	var groups []addGroup
+	for _, group := range proposed {
+		var paths []string
+		for _, unit := range group.Units {
+			if unit < 1 || unit > len(choices) || claimed[unit] {
+				logger.Debugf("Ignoring unit %d in group %q", unit, group.Message)
+				continue
+			}
+			claimed[unit] = true
+			paths = append(paths, choices[unit-1].Path)
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		if len(groups) == maxGroups {
+			logger.Debugf("Ignoring group %q past the first %d", group.Message, maxGroups)
+			continue
+		}
+		groups = append(groups, addGroup{message: firstLine(group.Message), paths: paths})
+	}
+	return groups
+}
+
+// groupOf returns the index of the group holding path, or -1
+func (m model) groupOf(path string) int {
+	for i, group := range m.groups {
+		for _, p := range group.paths {
+			if p == path {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// toggleGroup stages the files of a group, or unstages them if the whole
+// group is staged already
+func (m *model) toggleGroup(group int) {
+	if group >= len(m.groups) {
+		return
+	}
+	wanted := make(map[string]bool)
+	for _, path := range m.groups[group].paths {
+		wanted[path] = true
+	}
+	var files []int
+	for i, choice := range m.choices {
+		if wanted[choice.Path] {
+			files = append(files, i)
+		}
+	}
+	m.toggleFiles(files)
+}
+
+// groupsView lists the proposed groups with their keys
+func (m model) groupsView() string {
+	if len(m.groups) == 0 {
+		return ""
+	}
+	var s strings.Builder
+	s.WriteString("\nSuggested groups (press the number to stage one):\n")
+	for i, group := range m.groups {
+		s.WriteString(fmt.Sprintf("  %s %s (%d file(s))\n", groupStyle(i).Render(fmt.Sprintf("%d", i+1)), group.message, len(group.paths)))
+	}
+	return s.String()
+}
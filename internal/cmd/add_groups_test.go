@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ozankasikci/gitai/internal/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileGroups(t *testing.T) {
+	choices := []fileSelection{{Path: "a.go"}, {Path: "b.go"}, {Path: "c.go"}}
+
+	// One single-file group more than can be staged
+	var manyChoices []fileSelection
+	var tooMany []llm.CommitGroup
+	var capped []addGroup
+	for i := 1; i <= maxGroups+1; i++ {
+		path := fmt.Sprintf("file%d.go", i)
+		message := fmt.Sprintf("group %d", i)
+		manyChoices = append(manyChoices, fileSelection{Path: path})
+		tooMany = append(tooMany, llm.CommitGroup{Message: message, Units: []int{i}})
+		if i <= maxGroups {
+			capped = append(capped, addGroup{message: message, paths: []string{path}})
+		}
+	}
+
+	tests := []struct {
+		name     string
+		proposed []llm.CommitGroup
+		choices  []fileSelection
+		want     []addGroup
+	}{
+		{
+			name: "maps units to files and keeps the subject",
+			proposed: []llm.CommitGroup{
+				{Message: "feat: add a and c\n\nWith a body.", Units: []int{1, 3}},
+				{Message: "fix: b", Units: []int{2}},
+			},
+			choices: choices,
+			want: []addGroup{
+				{message: "feat: add a and c", paths: []string{"a.go", "c.go"}},
+				{message: "fix: b", paths: []string{"b.go"}},
+			},
+		},
+		{
+			name: "drops unknown and repeated units",
+			proposed: []llm.CommitGroup{
+				{Message: "one", Units: []int{0, 1, 4, 1}},
+				{Message: "two", Units: []int{1, 2, -1}},
+			},
+			choices: choices,
+			want: []addGroup{
+				{message: "one", paths: []string{"a.go"}},
+				{message: "two", paths: []string{"b.go"}},
+			},
+		},
+		{
+			name: "drops empty groups",
+			proposed: []llm.CommitGroup{
+				{Message: "empty", Units: nil},
+				{Message: "unknown", Units: []int{5}},
+				{Message: "kept", Units: []int{3}},
+			},
+			choices: choices,
+			want:    []addGroup{{message: "kept", paths: []string{"c.go"}}},
+		},
+		{
+			name:     "stops at maxGroups",
+			proposed: tooMany,
+			choices:  manyChoices,
+			want:     capped,
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, fileGroups(tt.proposed, tt.choices), tt.name)
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ozankasikci/gitai/internal/git"
+)
+
+var (
+	hunkHeaderStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	hunkAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	hunkRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	hunkDimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	hunkErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+// hunkRow is a line of the hunk view: a hunk header, or one of its lines
+// when the hunk is expanded
+type hunkRow struct {
+	staged bool
+	hunk   int
+	// line indexes Hunk.Lines; -1 for the header row
+	line int
+}
+
+// hunkModel is the drill-in view of one file. Unstaged hunks (index against
+// worktree) can be staged and staged hunks (HEAD against index) unstaged,
+// either whole or line by line.
+type hunkModel struct {
+	path     string
+	unstaged []git.Hunk
+	staged   []git.Hunk
+
+	cursor int
+	// expanded is the hunk whose lines are shown, if any
+	expanded *hunkRow
+	// marked holds the IDs of the lines selected in the expanded hunk
+	marked map[int]bool
+
+	err  error
+	done bool
+}
+
+func newHunkModel(path string) (*hunkModel, error) {
+	m := &hunkModel{path: path, marked: make(map[int]bool)}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// reload recomputes both hunk lists after the index changed
+func (m *hunkModel) reload() error {
+	unstaged, err := git.GetUnstagedHunks(m.path)
+	if err != nil && !errors.Is(err, git.ErrNotText) {
+		return err
+	}
+	staged, stagedErr := git.GetStagedHunks(m.path)
+	if stagedErr != nil && !errors.Is(stagedErr, git.ErrNotText) {
+		return stagedErr
+	}
+	if err != nil && stagedErr != nil {
+		return fmt.Errorf("%s cannot be staged by hunk", m.path)
+	}
+
+	m.unstaged, m.staged = unstaged, staged
+	m.expanded = nil
+	m.marked = make(map[int]bool)
+	if rows := m.rows(); m.cursor >= len(rows) {
+		m.cursor = max(len(rows)-1, 0)
+	}
+	return nil
+}
+
+func (m *hunkModel) hunk(row hunkRow) git.Hunk {
+	if row.staged {
+		return m.staged[row.hunk]
+	}
+	return m.unstaged[row.hunk]
+}
+
+// rows lists the unstaged hunks followed by the staged ones, with the lines
+// of the expanded hunk
+func (m *hunkModel) rows() []hunkRow {
+	var rows []hunkRow
+	for _, staged := range []bool{false, true} {
+		hunks := m.unstaged
+		if staged {
+			hunks = m.staged
+		}
+		for i, hunk := range hunks {
+			rows = append(rows, hunkRow{staged: staged, hunk: i, line: -1})
+			if m.expanded != nil && m.expanded.staged == staged && m.expanded.hunk == i {
+				for j := range hunk.Lines {
+					rows = append(rows, hunkRow{staged: staged, hunk: i, line: j})
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// apply stages or unstages the given lines of a hunk and reloads
+func (m *hunkModel) apply(staged bool, ids []int) {
+	if len(ids) == 0 {
+		return
+	}
+
+	var err error
+	if staged {
+		err = git.UnstageLines(m.path, ids)
+	} else {
+		err = git.StageLines(m.path, ids)
+	}
+	if err == nil {
+		err = m.reload()
+	}
+	m.err = err
+}
+
+func (m *hunkModel) Update(msg tea.KeyMsg) tea.Cmd {
+	rows := m.rows()
+	if len(rows) == 0 {
+		m.done = true
+		return nil
+	}
+	row := rows[m.cursor]
+	m.err = nil
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(rows)-1 {
+			m.cursor++
+		}
+	case " ":
+		if row.line < 0 {
+			m.apply(row.staged, m.hunk(row).ChangeIDs())
+			return nil
+		}
+		if line := m.hunk(row).Lines[row.line]; line.IsChange() {
+			m.marked[line.ID] = !m.marked[line.ID]
+		}
+	case "right", "l":
+		// Show the hunk's lines so they can be picked one by one
+		if row.line < 0 {
+			expanded := row
+			m.expanded = &expanded
+			m.marked = make(map[int]bool)
+		}
+	case "a":
+		if m.expanded != nil {
+			var ids []int
+			for _, id := range m.hunk(*m.expanded).ChangeIDs() {
+				if m.marked[id] {
+					ids = append(ids, id)
+				}
+			}
+			m.apply(m.expanded.staged, ids)
+		}
+	case "left", "h", "esc":
+		if m.expanded != nil {
+			// Move the cursor back to the collapsed hunk
+			for i, r := range rows {
+				if r.staged == m.expanded.staged && r.hunk == m.expanded.hunk && r.line < 0 {
+					m.cursor = i
+				}
+			}
+			m.expanded = nil
+			return nil
+		}
+		m.done = true
+	}
+	return nil
+}
+
+func (m *hunkModel) View() string {
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("Hunks of %s\n", m.path))
+	if m.expanded != nil {
+		s.WriteString("Use space to mark lines, 'a' to apply marked lines, ← to collapse\n\n")
+	} else {
+		s.WriteString("Use space to stage/unstage a hunk, → to pick lines, ← to go back\n\n")
+	}
+
+	rows := m.rows()
+	if len(rows) == 0 {
+		s.WriteString("No changes left\n")
+	}
+
+	section := -1
+	for i, row := range rows {
+		if current := map[bool]int{false: 0, true: 1}[row.staged]; current != section {
+			section = current
+			s.WriteString(hunkDimStyle.Render(map[bool]string{false: "Unstaged", true: "Staged"}[row.staged]) + "\n")
+		}
+
+		cursor := " "
+		if m.cursor == i {
+			cursor = ">"
+		}
+
+		hunk := m.hunk(row)
+		if row.line < 0 {
+			s.WriteString(fmt.Sprintf("%s %s\n", cursor, hunkHeaderStyle.Render(hunk.Header)))
+			if m.expanded == nil || m.expanded.staged != row.staged || m.expanded.hunk != row.hunk {
+				s.WriteString(renderHunkPreview(hunk))
+			}
+			continue
+		}
+
+		line := hunk.Lines[row.line]
+		mark := "   "
+		if line.IsChange() {
+			mark = "[ ]"
+			if m.marked[line.ID] {
+				mark = "[x]"
+			}
+		}
+		s.WriteString(fmt.Sprintf("%s %s %s\n", cursor, mark, renderHunkLine(line)))
+	}
+
+	if m.err != nil {
+		s.WriteString("\n" + hunkErrorStyle.Render(m.err.Error()) + "\n")
+	}
+	return s.String()
+}
+
+// hunkPreviewLines is how many changed lines of a collapsed hunk are shown
+const hunkPreviewLines = 6
+
+func renderHunkPreview(hunk git.Hunk) string {
+	var s strings.Builder
+	shown := 0
+	for _, line := range hunk.Lines {
+		if !line.IsChange() {
+			continue
+		}
+		if shown == hunkPreviewLines {
+			s.WriteString("      " + hunkDimStyle.Render("...") + "\n")
+			break
+		}
+		s.WriteString("      " + renderHunkLine(line) + "\n")
+		shown++
+	}
+	return s.String()
+}
+
+func renderHunkLine(line git.HunkLine) string {
+	text := string(line.Op) + line.Text
+	switch line.Op {
+	case '+':
+		return hunkAddedStyle.Render(text)
+	case '-':
+		return hunkRemovedStyle.Render(text)
+	default:
+		return hunkDimStyle.Render(text)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ozankasikci/gitai/internal/git"
+)
+
+// Default size of the preview pane until the terminal size is known
+const (
+	previewWidth  = 80
+	previewHeight = 15
+)
+
+var previewBorderStyle = lipgloss.NewStyle().
+	BorderStyle(lipgloss.NormalBorder()).
+	BorderTop(true).
+	BorderForeground(lipgloss.Color("241"))
+
+func newPreview() viewport.Model {
+	return viewport.New(previewWidth, previewHeight)
+}
+
+// resizePreview gives the preview the bottom part of a terminal of the given
+// size, leaving the rest to the file list
+func resizePreview(preview *viewport.Model, width, height int) {
+	preview.Width = width
+	preview.Height = max(height/2-2, 3)
+}
+
+// renderFilePreview shows the staged and unstaged changes of a file as
+// coloured unified diffs
+func renderFilePreview(choice fileSelection) string {
+	var s strings.Builder
+	s.WriteString(hunkHeaderStyle.Bold(true).Render(choice.DisplayPath) + "\n")
+
+	shown := false
+	for _, section := range []struct {
+		title string
+		get   func(string) ([]git.Hunk, error)
+	}{
+		{"Staged", git.GetStagedHunks},
+		{"Unstaged", git.GetUnstagedHunks},
+	} {
+		hunks, err := section.get(choice.Path)
+		if errors.Is(err, git.ErrNotText) {
+			continue
+		}
+		if err != nil {
+			s.WriteString(hunkErrorStyle.Render(err.Error()) + "\n")
+			shown = true
+			continue
+		}
+		if len(hunks) == 0 {
+			continue
+		}
+
+		shown = true
+		s.WriteString(hunkDimStyle.Render(section.title) + "\n")
+		for _, hunk := range hunks {
+			s.WriteString(hunkHeaderStyle.Render(hunk.Header) + "\n")
+			for _, line := range hunk.Lines {
+				s.WriteString(renderHunkLine(line) + "\n")
+			}
+		}
+	}
+
+	if !shown {
+		switch choice.Status {
+		case "deleted":
+			s.WriteString(hunkDimStyle.Render("File deleted") + "\n")
+		default:
+			s.WriteString(hunkDimStyle.Render("No text changes to show (binary file, symlink or mode change)") + "\n")
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+var statusColumnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+// addRow is a line of the add list: a directory, or a file by its index in
+// model.choices
+type addRow struct {
+	dir   string
+	file  int
+	depth int
+}
+
+func (r addRow) isDir() bool {
+	return r.file < 0
+}
+
+// rows lists what the add view shows: the matching files ranked by fuzzy
+// score while a filter is set, or else the changed files as a directory tree
+// with collapsed directories hidden
+func (m model) rows() []addRow {
+	if m.filter != "" {
+		paths := make([]string, len(m.choices))
+		for i, choice := range m.choices {
+			paths[i] = choice.DisplayPath
+		}
+		ranks := fuzzy.RankFindNormalizedFold(m.filter, paths)
+		sort.SliceStable(ranks, func(i, j int) bool {
+			if ranks[i].Distance != ranks[j].Distance {
+				return ranks[i].Distance < ranks[j].Distance
+			}
+			return ranks[i].OriginalIndex < ranks[j].OriginalIndex
+		})
+
+		rows := make([]addRow, len(ranks))
+		for i, rank := range ranks {
+			rows[i] = addRow{file: rank.OriginalIndex}
+		}
+		return rows
+	}
+
+	// GetAllChanges sorts by path, so each directory's files are contiguous
+	var rows []addRow
+	seen := make(map[string]bool)
+	for i, choice := range m.choices {
+		dirs := parentDirs(choice.Path)
+		hidden := false
+		for depth, dir := range dirs {
+			if !seen[dir] {
+				seen[dir] = true
+				if !hidden {
+					rows = append(rows, addRow{dir: dir, file: -1, depth: depth})
+				}
+			}
+			hidden = hidden || m.collapsed[dir]
+		}
+		if !hidden {
+			rows = append(rows, addRow{file: i, depth: len(dirs)})
+		}
+	}
+	return rows
+}
+
+// parentDirs returns the directories containing p, outermost first
+func parentDirs(p string) []string {
+	var dirs []string
+	for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// rowFiles returns the indexes of the files a row covers
+func (m model) rowFiles(row addRow) []int {
+	if !row.isDir() {
+		return []int{row.file}
+	}
+	var files []int
+	for i, choice := range m.choices {
+		if strings.HasPrefix(choice.Path, row.dir+"/") {
+			files = append(files, i)
+		}
+	}
+	return files
+}
+
+// rowLabel renders a row's checkbox and name
+func (m model) rowLabel(row addRow) string {
+	staged, partial := 0, false
+	files := m.rowFiles(row)
+	for _, i := range files {
+		if m.choices[i].IsStaged {
+			staged++
+		}
+		partial = partial || m.choices[i].Partial
+	}
+
+	checked := " "
+	switch {
+	case partial || (staged > 0 && staged < len(files)):
+		checked = "~"
+	case staged > 0:
+		checked = "x"
+	}
+
+	indent := strings.Repeat("  ", row.depth)
+	if row.isDir() {
+		arrow := "▾"
+		if m.collapsed[row.dir] {
+			arrow = "▸"
+		}
+		return fmt.Sprintf("%s[%s] %s %s/ (%d)", indent, checked, arrow, path.Base(row.dir), len(files))
+	}
+
+	choice := m.choices[row.file]
+	name := choice.DisplayPath
+	if m.filter == "" && name == choice.Path {
+		name = path.Base(name)
+	}
+	label := fmt.Sprintf("%s[%s] %s %s", indent, checked, statusColumnStyle.Render(choice.ShortStatus), name)
+	if group := m.groupOf(choice.Path); group >= 0 {
+		label += " " + groupStyle(group).Render(fmt.Sprintf("● %d", group+1))
+	}
+	if err := m.errs[choice.Path]; err != nil {
+		label += " " + hunkErrorStyle.Render("✗ "+err.Error())
+	}
+	return label
+}
+
+// listHeight is how many rows fit above the preview; 0 means unlimited
+// because the terminal size is not known yet
+func (m model) listHeight() int {
+	if m.height == 0 {
+		return 0
+	}
+	// Header, status, groups, preview border and footer lines
+	reserved := 7
+	if len(m.groups) > 0 {
+		reserved += len(m.groups) + 2
+	}
+	return max(m.height-m.preview.Height-reserved, 3)
+}
+
+// scrollToCursor keeps the cursor row inside the visible part of the list
+func (m *model) scrollToCursor() {
+	height := m.listHeight()
+	if height == 0 {
+		m.offset = 0
+		return
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	m.offset = max(min(m.offset, len(m.rows())-height), 0)
+}
@@ -8,16 +8,24 @@ import (
 )
 
 func NewAutoCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "auto",
+	cmd := &cobra.Command{
+		Use:   "auto [pathspec...]",
 		Short: "Automatically stage and commit changes",
 		
 		Long:  `Stage files and generate commit message in one command`,
 		RunE:  runAuto,
 	}
+	addCommitFlags(cmd)
+	addStagingFlags(cmd)
+	return cmd
 }
 
 func runAuto(cmd *cobra.Command, args []string) error {
+	// Fail before staging anything if the commit would need a prompt
+	if err := checkSelectionFlags(); err != nil {
+		return err
+	}
+
 	// First run the add command
 	if err := runAdd(cmd, args); err != nil {
 		logger.Errorf("Error from runAdd: %v", err)
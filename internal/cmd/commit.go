@@ -1,16 +1,17 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strconv"
+	"strings"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/ozankasikci/gitai/internal/config"
 	"github.com/ozankasikci/gitai/internal/git"
 	"github.com/ozankasikci/gitai/internal/llm"
 	"github.com/ozankasikci/gitai/internal/logger"
+	"github.com/ozankasikci/gitai/internal/redact"
 	"github.com/spf13/cobra"
 	"github.com/pterm/pterm"
 )
@@ -62,43 +63,185 @@ func (m commitModel) View() string {
 	return ""
 }
 
+// commitFlags holds the flags shared by the commit and auto commands
+var commitFlags struct {
+	signoff   bool
+	coAuthors []string
+	trailers  []string
+	gpgSign   bool
+	noGPGSign bool
+	noVerify  bool
+	yes       bool
+	pick      int
+
+	// Only registered on the commit command
+	amend       bool
+	resetAuthor bool
+	dryRun      bool
+	messageOnly bool
+	output      string
+	noEdit      bool
+}
+
 func NewCommitCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "commit",
 		Short: "Generate and apply commit messages",
 		Long: `Generate commit messages using AI based on your staged changes.
 The messages will follow conventional commits format and best practices.`,
 		RunE: runCommit,
 	}
+	addCommitFlags(cmd)
+	cmd.Flags().BoolVar(&commitFlags.amend, "amend", false, "Regenerate the message of the last commit and amend it with the staged changes")
+	cmd.Flags().BoolVar(&commitFlags.resetAuthor, "reset-author", false, "When amending, make yourself the author and reset the author date")
+	cmd.Flags().BoolVar(&commitFlags.dryRun, "dry-run", false, "Print the suggestions without committing")
+	cmd.Flags().BoolVar(&commitFlags.noEdit, "no-edit", false, "Commit the selected suggestion without opening it in the editor")
+	cmd.Flags().StringVarP(&commitFlags.output, "output", "o", "", "Print the suggestions, provider, model, token usage and files as json or yaml without committing")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(outputFormats, cobra.ShellCompDirectiveNoFileComp))
+	cmd.Flags().BoolVar(&commitFlags.messageOnly, "message-only", false, "Print the chosen message (the top one unless --pick is given) to stdout without committing")
+	return cmd
 }
 
-func runCommit(cmd *cobra.Command, args []string) error {
-	changes, err := git.GetStagedChanges()
+func addCommitFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&commitFlags.signoff, "signoff", "s", false, "Add a Signed-off-by trailer")
+	cmd.Flags().StringArrayVar(&commitFlags.coAuthors, "co-author", nil, "Add a Co-authored-by trailer (repeatable)")
+	cmd.Flags().StringArrayVar(&commitFlags.trailers, "trailer", nil, "Add a \"Key: value\" trailer (repeatable)")
+	cmd.Flags().BoolVarP(&commitFlags.gpgSign, "gpg-sign", "S", false, "Sign the commit even if commit.gpgsign is not set")
+	cmd.Flags().BoolVar(&commitFlags.noGPGSign, "no-gpg-sign", false, "Do not sign the commit, overriding commit.gpgsign")
+	cmd.Flags().BoolVarP(&commitFlags.noVerify, "no-verify", "n", false, "Skip the pre-commit and commit-msg hooks")
+	cmd.Flags().BoolVarP(&commitFlags.yes, "yes", "y", false, "Commit with the top suggestion without prompting")
+	cmd.Flags().IntVar(&commitFlags.pick, "pick", 0, "Commit with the Nth suggestion without prompting")
+	_ = cmd.RegisterFlagCompletionFunc("co-author", completeCoAuthors)
+}
+
+// completeCoAuthors completes --co-author from .mailmap and the repository history
+func completeCoAuthors(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	authors, err := git.GetKnownAuthors()
 	if err != nil {
-		return fmt.Errorf("failed to get staged changes: %w", err)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	needle := strings.ToLower(toComplete)
+	var completions []string
+	for _, author := range authors {
+		if strings.Contains(strings.ToLower(author), needle) {
+			completions = append(completions, author)
+		}
 	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
 
-	if len(changes) == 0 {
-		return fmt.Errorf("no staged changes found. Use 'git add' to stage changes")
+// contentOptions builds the prompt content options from the config
+func contentOptions() (git.ContentOptions, error) {
+	cfg := config.Get()
+	opts := git.ContentOptions{MaxFileSize: cfg.Prompt.MaxFileSize}
+	if cfg.Redaction.Disabled {
+		return opts, nil
 	}
 
-	content, err := git.GetStagedContent()
+	redactor, err := redact.New(cfg.Redaction.Patterns)
 	if err != nil {
-		return fmt.Errorf("failed to get staged content: %w", err)
+		return opts, fmt.Errorf("failed to set up redaction: %w", err)
 	}
-	logger.Debugf("\n=== Staged content from git.GetStagedContent() ===\nLength: %d\nContent:\n%s\n", len(content), content)
+	opts.Redactor = redactor
+	return opts, nil
+}
 
-	client, err := llm.NewLLMClient()
+// warnRedactions tells the user which files had secrets removed before
+// their diffs were sent to the provider
+func warnRedactions(opts git.ContentOptions) {
+	if opts.Redactor == nil {
+		return
+	}
+
+	for _, finding := range opts.Redactor.Findings() {
+		pterm.Warning.Printf("Redacted secrets from %s (%s)\n", finding.Path, strings.Join(finding.Detectors, ", "))
+	}
+}
+
+// warnUnstagedChanges points out staged files whose working copy differs
+// from what will be committed
+func warnUnstagedChanges() {
+	changes, err := git.GetAllChanges()
 	if err != nil {
-		return fmt.Errorf("failed to create LLM client: %w", err)
+		logger.Debugf("Failed to check for unstaged changes: %v", err)
+		return
+	}
+
+	for _, change := range changes {
+		if change.Staged && change.HasUnstagedChanges() {
+			pterm.Warning.Printf("%s has changes that are not staged and will not be committed (%s)\n", change.DisplayPath(), change.ShortStatus())
+		}
+	}
+}
+
+// commitOptions merges the configured trailers and signing key with the
+// command line flags
+func commitOptions() git.CommitOptions {
+	cfg := config.Get()
+	return git.CommitOptions{
+		Signoff:   cfg.Commit.Signoff || commitFlags.signoff,
+		CoAuthors: commitFlags.coAuthors,
+		Trailers:  append(append([]string{}, cfg.Commit.Trailers...), commitFlags.trailers...),
+
+		Sign:           commitFlags.gpgSign,
+		NoSign:         commitFlags.noGPGSign,
+		SigningKeyFile: cfg.Commit.SigningKeyFile,
+		NoVerify:       commitFlags.noVerify,
+
+		Amend:       commitFlags.amend,
+		ResetAuthor: commitFlags.resetAuthor,
+	}
+}
+
+// checkSelectionFlags validates the flags that choose a suggestion, and
+// fails early when a prompt would be needed but stdin is not a terminal
+func checkSelectionFlags() error {
+	if commitFlags.pick < 0 {
+		return fmt.Errorf("--pick must be a suggestion number, starting at 1")
+	}
+	if commitFlags.yes && commitFlags.pick > 0 {
+		return fmt.Errorf("--yes and --pick cannot be used together")
+	}
+	if commitFlags.dryRun && (commitFlags.yes || commitFlags.pick > 0 || commitFlags.messageOnly) {
+		return fmt.Errorf("--dry-run cannot be used with --yes, --pick or --message-only")
+	}
+	if commitFlags.output != "" {
+		if !validOutputFormat(commitFlags.output) {
+			return fmt.Errorf("unsupported output format %q, use %s", commitFlags.output, strings.Join(outputFormats, " or "))
+		}
+		if commitFlags.yes || commitFlags.pick > 0 || commitFlags.messageOnly {
+			return fmt.Errorf("--output cannot be used with --yes, --pick or --message-only")
+		}
 	}
 
-	p := tea.NewProgram(initialCommitModel())
-	
+	prompts := !commitFlags.yes && commitFlags.pick == 0 && !commitFlags.dryRun && !commitFlags.messageOnly && commitFlags.output == ""
+	if prompts && !isInteractive() {
+		return fmt.Errorf("cannot prompt for a commit message without a terminal; use --yes, --pick N, --dry-run, --message-only or --output")
+	}
+	return nil
+}
+
+// reserveStdout moves all other output to stderr, so that stdout only holds
+// what is printed for scripts
+func reserveStdout() {
+	logger.UseStderr()
+	pterm.SetDefaultOutput(os.Stderr)
+}
+
+// generateSuggestions asks the provider for suggestions, with a spinner when
+// running in a terminal
+func generateSuggestions(client llm.CommitMessageGenerator, content string) ([]llm.CommitSuggestion, error) {
+	logger.Debugf("\n=== Content being sent to GenerateCommitSuggestions ===\n%s\n", content)
+	if !isInteractive() {
+		return client.GenerateCommitSuggestions(content)
+	}
+
+	p := tea.NewProgram(initialCommitModel(), tea.WithOutput(os.Stderr))
+
 	// Run LLM in goroutine
 	go func() {
 		logger.Infof("Starting LLM goroutine")
-		logger.Debugf("\n=== Content being sent to GenerateCommitSuggestions ===\n%s\n", content)
 		suggestions, err := client.GenerateCommitSuggestions(content)
 		if err != nil {
 			logger.Errorf("Error in LLM goroutine: %v", err)
@@ -111,17 +254,32 @@ func runCommit(cmd *cobra.Command, args []string) error {
 
 	model, err := p.Run()
 	if err != nil {
-		return fmt.Errorf("error running program: %w", err)
+		return nil, fmt.Errorf("error running program: %w", err)
 	}
 
 	m := model.(commitModel)
 	if m.err != nil {
-		return m.err
+		return nil, m.err
 	}
+	return m.suggestions, nil
+}
 
-	suggestions := m.suggestions
+// pickedSuggestion returns the suggestion chosen with --pick, or the top one
+func pickedSuggestion(suggestions []llm.CommitSuggestion) (string, error) {
+	if len(suggestions) == 0 {
+		return "", fmt.Errorf("no commit message suggestions were generated")
+	}
+	if commitFlags.pick == 0 {
+		return suggestions[0].Message, nil
+	}
+	if commitFlags.pick > len(suggestions) {
+		return "", fmt.Errorf("cannot pick suggestion %d, only %d were generated", commitFlags.pick, len(suggestions))
+	}
+	return suggestions[commitFlags.pick-1].Message, nil
+}
 
-	// Display suggestions
+// printSuggestions lists the numbered suggestions with their explanations
+func printSuggestions(suggestions []llm.CommitSuggestion) {
 	fmt.Println("\nGenerated commit message suggestions:")
 	for i, suggestion := range suggestions {
 		fmt.Printf("\n%d. %s\n", i+1, suggestion.Message)
@@ -131,40 +289,153 @@ func runCommit(cmd *cobra.Command, args []string) error {
 			pterm.FgGray.Println(suggestion.Explanation)
 		}
 	}
+}
 
-	// Rest of the commit logic remains the same
-	fmt.Printf("\nSelect a commit message (1-%d), 0 to cancel, or type your own message: ", len(suggestions))
-	
-	scanner := bufio.NewScanner(os.Stdin)
-	if !scanner.Scan() {
-		return fmt.Errorf("failed to read input")
+// committedChanges returns the changes the next commit is generated from:
+// the staged ones, plus HEAD's when amending
+func committedChanges() ([]git.StagedChange, error) {
+	if commitFlags.amend {
+		return git.GetAmendChanges()
 	}
-	input := scanner.Text()
+	return git.GetStagedChanges()
+}
 
-	// Handle empty input (just pressing enter)
-	if input == "" {
-		fmt.Println("Empty input - commit cancelled")
-		return nil
+// writeSuggestionFile writes a suggestion for editing like git commit does,
+// with its explanation and the committed files as comments
+func writeSuggestionFile(suggestion llm.CommitSuggestion) (string, error) {
+	comments := []string{
+		"Please enter the commit message for your changes. Lines starting",
+		"with '#' will be ignored, and an empty message aborts the commit.",
+	}
+	if suggestion.Explanation != "" {
+		comments = append(comments, "", "Explanation:")
+		for _, line := range strings.Split(strings.TrimSpace(suggestion.Explanation), "\n") {
+			comments = append(comments, "  "+line)
+		}
+	}
+	if changes, err := committedChanges(); err == nil && len(changes) > 0 {
+		comments = append(comments, "", "Changes to be committed:")
+		for _, change := range changes {
+			comments = append(comments, fmt.Sprintf("\t%-12s%s", change.Status+":", change.DisplayPath()))
+		}
 	}
 
-	var selectedMessage string
-	selection, err := strconv.Atoi(input)
+	return writeMessageFile(suggestion.Message, comments...)
+}
+
+// pickMessage shows the suggestions full-screen until one is committed,
+// edited or the user cancels, which returns an empty message
+func pickMessage(client llm.CommitMessageGenerator, content string, suggestions []llm.CommitSuggestion) (string, error) {
+	stats, err := git.GetStagedDiffStats(commitFlags.amend)
+	if err != nil {
+		logger.Debugf("Failed to get diff stats: %v", err)
+	}
+	var current string
+	if commitFlags.amend {
+		current, _ = git.HeadCommitMessage()
+	}
+
+	chat := llm.NewSuggestionChat(client, content, suggestions)
+	p := tea.NewProgram(initialPickerModel(chat, suggestions, stats, current, commitFlags.noEdit), tea.WithAltScreen())
+	model, err := p.Run()
 	if err != nil {
-		selectedMessage = input
-	} else {
-		if selection == 0 {
-			fmt.Println("Commit cancelled")
-			return nil
+		return "", fmt.Errorf("error running program: %w", err)
+	}
+
+	m := model.(pickerModel)
+	if m.quitting {
+		fmt.Println("Commit cancelled")
+		return "", nil
+	}
+	return m.message, nil
+}
+
+func runCommit(cmd *cobra.Command, args []string) error {
+	if commitFlags.resetAuthor && !commitFlags.amend {
+		return fmt.Errorf("--reset-author can only be used with --amend")
+	}
+	if err := checkSelectionFlags(); err != nil {
+		return err
+	}
+	if commitFlags.messageOnly || commitFlags.output != "" {
+		reserveStdout()
+	}
+
+	// Amending only needs the changes already in HEAD
+	if !commitFlags.amend {
+		changes, err := git.GetStagedChanges()
+		if err != nil {
+			return fmt.Errorf("failed to get staged changes: %w", err)
+		}
+
+		if len(changes) == 0 {
+			return fmt.Errorf("no staged changes found. Use 'git add' to stage changes")
 		}
+	}
+
+	warnUnstagedChanges()
+
+	opts, err := contentOptions()
+	if err != nil {
+		return err
+	}
+	opts.Amend = commitFlags.amend
+
+	content, err := git.GetStagedContent(opts)
+	if err != nil {
+		return fmt.Errorf("failed to get staged content: %w", err)
+	}
+	warnRedactions(opts)
+	logger.Debugf("\n=== Staged content from git.GetStagedContent() ===\nLength: %d\nContent:\n%s\n", len(content), content)
 
-		if selection < 1 || selection > len(suggestions) {
-			return fmt.Errorf("invalid selection: %d", selection)
+	client, err := llm.NewLLMClient()
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	suggestions, err := generateSuggestions(client, content)
+	if err != nil {
+		return err
+	}
+
+	if commitFlags.output != "" {
+		return writeSuggestions(os.Stdout, commitFlags.output, client, suggestions)
+	}
+
+	if commitFlags.messageOnly {
+		message, err := pickedSuggestion(suggestions)
+		if err != nil {
+			return err
 		}
+		fmt.Println(message)
+		return nil
+	}
 
-		selectedMessage = suggestions[selection-1].Message
+	var selectedMessage string
+	switch {
+	case commitFlags.dryRun:
+		if commitFlags.amend {
+			if current, err := git.HeadCommitMessage(); err == nil {
+				fmt.Println("\nCurrent commit message:")
+				pterm.FgGray.Println(strings.TrimSpace(current))
+			}
+		}
+		printSuggestions(suggestions)
+		return nil
+	case commitFlags.yes || commitFlags.pick > 0:
+		printSuggestions(suggestions)
+		selectedMessage, err = pickedSuggestion(suggestions)
+	default:
+		selectedMessage, err = pickMessage(client, content, suggestions)
+	}
+	if err != nil {
+		return err
+	}
+	if selectedMessage == "" {
+		return nil
 	}
 
-	if err := git.CommitChanges(selectedMessage); err != nil {
+	if err := git.CommitChanges(selectedMessage, commitOptions()); err != nil {
 		return fmt.Errorf("failed to commit changes: %w", err)
 	}
 
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ozankasikci/gitai/internal/config"
+	"github.com/ozankasikci/gitai/internal/llm"
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormats are the values accepted by --output
+var outputFormats = []string{"json", "yaml"}
+
+// suggestionsOutput is what --output prints for other programs
+type suggestionsOutput struct {
+	Provider    string             `json:"provider" yaml:"provider"`
+	Model       string             `json:"model" yaml:"model"`
+	Suggestions []suggestionOutput `json:"suggestions" yaml:"suggestions"`
+	Usage       usageOutput        `json:"usage" yaml:"usage"`
+	Files       []fileOutput       `json:"files" yaml:"files"`
+}
+
+type suggestionOutput struct {
+	Message     string `json:"message" yaml:"message"`
+	Explanation string `json:"explanation,omitempty" yaml:"explanation,omitempty"`
+}
+
+type usageOutput struct {
+	InputTokens  int64 `json:"input_tokens" yaml:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens" yaml:"output_tokens"`
+}
+
+type fileOutput struct {
+	Path    string `json:"path" yaml:"path"`
+	OldPath string `json:"old_path,omitempty" yaml:"old_path,omitempty"`
+	Status  string `json:"status" yaml:"status"`
+}
+
+// validOutputFormat reports whether format can be passed to --output
+func validOutputFormat(format string) bool {
+	for _, known := range outputFormats {
+		if format == known {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSuggestions prints the suggestions along with the provider, its token
+// usage and the files they were generated from in the --output format
+func writeSuggestions(w io.Writer, format string, client llm.CommitMessageGenerator, suggestions []llm.CommitSuggestion) error {
+	changes, err := committedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to get staged changes: %w", err)
+	}
+
+	provider, model := config.Get().GetProviderAndModel()
+	usage := client.Usage()
+	out := suggestionsOutput{
+		Provider:    provider,
+		Model:       model,
+		Suggestions: []suggestionOutput{},
+		Usage:       usageOutput{InputTokens: usage.InputTokens, OutputTokens: usage.OutputTokens},
+		Files:       []fileOutput{},
+	}
+	for _, suggestion := range suggestions {
+		out.Suggestions = append(out.Suggestions, suggestionOutput{Message: suggestion.Message, Explanation: suggestion.Explanation})
+	}
+	for _, change := range changes {
+		out.Files = append(out.Files, fileOutput{Path: change.Path, OldPath: change.OldPath, Status: change.Status})
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(out)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		err = encoder.Encode(out)
+		if err == nil {
+			err = encoder.Close()
+		}
+	default:
+		err = fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write suggestions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ozankasikci/gitai/internal/git"
+	"github.com/ozankasikci/gitai/internal/llm"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+var (
+	pickerAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	pickerDeletedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	pickerMessageStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// pickerMaxStats is how many files the diff stats list before summarizing
+const pickerMaxStats = 5
+
+type pickerSuggestionsMsg struct {
+	suggestions []llm.CommitSuggestion
+	// more appends the suggestions instead of replacing the current ones
+	more bool
+	err  error
+}
+
+type pickerEditedMsg struct {
+	message string
+	err     error
+}
+
+// pickerModel lets the user choose, edit and refine the suggestions before
+// committing
+type pickerModel struct {
+	chat        *llm.SuggestionChat
+	suggestions []llm.CommitSuggestion
+	stats       []git.DiffStat
+	// current is the message of the commit being amended
+	current string
+	// noEdit commits the selected suggestion without opening the editor
+	noEdit bool
+
+	cursor  int
+	height  int
+	spinner spinner.Model
+	// loading describes the request in flight, empty when idle
+	loading string
+	// feedback is being typed while giving feedback is set
+	feedback       string
+	givingFeedback bool
+	status         string
+
+	// message is the accepted message once done
+	message  string
+	done     bool
+	quitting bool
+}
+
+func initialPickerModel(chat *llm.SuggestionChat, suggestions []llm.CommitSuggestion, stats []git.DiffStat, current string, noEdit bool) pickerModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	return pickerModel{
+		chat:        chat,
+		suggestions: suggestions,
+		stats:       stats,
+		current:     current,
+		noEdit:      noEdit,
+		spinner:     s,
+	}
+}
+
+func (m pickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// request runs a conversation turn, showing what is being done meanwhile
+func (m pickerModel) request(description string, more bool, ask func() ([]llm.CommitSuggestion, error)) (tea.Model, tea.Cmd) {
+	m.loading = description
+	m.status = ""
+	return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+		suggestions, err := ask()
+		return pickerSuggestionsMsg{suggestions: suggestions, more: more, err: err}
+	})
+}
+
+func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.loading == "" {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case pickerSuggestionsMsg:
+		m.loading = ""
+		if msg.err != nil {
+			logger.Debugf("Failed to update suggestions: %v", msg.err)
+			m.status = fmt.Sprintf("Failed to generate suggestions: %v", msg.err)
+			return m, nil
+		}
+		if msg.more {
+			m.cursor = len(m.suggestions)
+			m.suggestions = append(m.suggestions, msg.suggestions...)
+		} else {
+			m.cursor = 0
+			m.suggestions = msg.suggestions
+		}
+		return m, nil
+
+	case pickerEditedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		if msg.message == "" {
+			m.status = "Empty message, nothing committed"
+			return m, nil
+		}
+		m.message = msg.message
+		m.done = true
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		if m.givingFeedback {
+			return m.updateFeedback(msg)
+		}
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.loading != "" {
+			return m, nil
+		}
+
+		m.status = ""
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.suggestions)-1 {
+				m.cursor++
+			}
+		case "enter":
+			if len(m.suggestions) == 0 {
+				break
+			}
+			if !m.noEdit {
+				return m, m.edit(m.suggestions[m.cursor])
+			}
+			m.message = m.suggestions[m.cursor].Message
+			m.done = true
+			return m, tea.Quit
+		case "e":
+			if len(m.suggestions) > 0 {
+				return m, m.edit(m.suggestions[m.cursor])
+			}
+		case "c":
+			// Write a message from scratch
+			return m, m.edit(llm.CommitSuggestion{})
+		case "r":
+			return m.request("Regenerating suggestions...", false, m.chat.Regenerate)
+		case "m":
+			return m.request("Generating more suggestions...", true, m.chat.More)
+		case "f":
+			m.givingFeedback = true
+			m.feedback = ""
+		}
+	}
+	return m, nil
+}
+
+// updateFeedback handles typing feedback; enter sends it to the provider
+func (m pickerModel) updateFeedback(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.givingFeedback = false
+	case tea.KeyEnter:
+		feedback := strings.TrimSpace(m.feedback)
+		if feedback == "" {
+			return m, nil
+		}
+		m.givingFeedback = false
+		chat := m.chat
+		return m.request("Revising suggestions...", false, func() ([]llm.CommitSuggestion, error) {
+			return chat.Refine(feedback)
+		})
+	case tea.KeyBackspace:
+		if m.feedback != "" {
+			runes := []rune(m.feedback)
+			m.feedback = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.feedback += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// edit opens suggestion in the editor and accepts the result
+func (m pickerModel) edit(suggestion llm.CommitSuggestion) tea.Cmd {
+	path, err := writeSuggestionFile(suggestion)
+	if err != nil {
+		return func() tea.Msg { return pickerEditedMsg{err: err} }
+	}
+
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		if err != nil {
+			os.Remove(path)
+			return pickerEditedMsg{err: fmt.Errorf("editor failed: %w", err)}
+		}
+		message, err := readMessageFile(path)
+		return pickerEditedMsg{message: message, err: err}
+	})
+}
+
+// statsView summarizes the diff stats of the changes being committed
+func (m pickerModel) statsView() string {
+	var added, deleted int
+	for _, stat := range m.stats {
+		added += stat.Added
+		deleted += stat.Deleted
+	}
+
+	var s strings.Builder
+	s.WriteString(fmt.Sprintf("%d file(s) changed, %s, %s\n", len(m.stats),
+		pickerAddedStyle.Render(fmt.Sprintf("%d insertion(s)(+)", added)),
+		pickerDeletedStyle.Render(fmt.Sprintf("%d deletion(s)(-)", deleted))))
+	for i, stat := range m.stats {
+		if i == pickerMaxStats {
+			s.WriteString(rewordDimStyle.Render(fmt.Sprintf("  ... and %d more", len(m.stats)-pickerMaxStats)) + "\n")
+			break
+		}
+		if stat.Binary {
+			s.WriteString(fmt.Sprintf("  %s %s\n", stat.Path, rewordDimStyle.Render("(binary)")))
+			continue
+		}
+		s.WriteString(fmt.Sprintf("  %s %s %s\n", stat.Path,
+			pickerAddedStyle.Render(fmt.Sprintf("+%d", stat.Added)),
+			pickerDeletedStyle.Render(fmt.Sprintf("-%d", stat.Deleted))))
+	}
+	return s.String()
+}
+
+// suggestionLines renders the suggestions, returning the line each one
+// ends at so the list can be scrolled to the cursor
+func (m pickerModel) suggestionLines() (lines []string, ends []int) {
+	for i, suggestion := range m.suggestions {
+		cursor := "  "
+		message := firstLine(suggestion.Message)
+		if i == m.cursor {
+			cursor = rewordCursorStyle.Render("> ")
+			message = pickerMessageStyle.Render(message)
+		}
+		lines = append(lines, fmt.Sprintf("%s%d. %s", cursor, i+1, message))
+
+		body := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(suggestion.Message), firstLine(suggestion.Message)))
+		if body != "" {
+			for _, line := range strings.Split(body, "\n") {
+				lines = append(lines, "     "+line)
+			}
+		}
+		if suggestion.Explanation != "" {
+			lines = append(lines, "     "+rewordDimStyle.Render(suggestion.Explanation))
+		}
+		lines = append(lines, "")
+		ends = append(ends, len(lines))
+	}
+	return lines, ends
+}
+
+func (m pickerModel) View() string {
+	if m.done || m.quitting {
+		return ""
+	}
+
+	var header strings.Builder
+	header.WriteString(rewordHeaderStyle.Render("Commit message suggestions") + "\n")
+	header.WriteString(m.statsView())
+	if m.current != "" {
+		header.WriteString(rewordDimStyle.Render("Current message: "+firstLine(m.current)) + "\n")
+	}
+	header.WriteString("\n")
+
+	var footer strings.Builder
+	switch {
+	case m.loading != "":
+		footer.WriteString(fmt.Sprintf("\n%s %s\n", m.spinner.View(), m.loading))
+	case m.givingFeedback:
+		footer.WriteString(fmt.Sprintf("\nFeedback: %s█\n", m.feedback))
+		footer.WriteString(rewordDimStyle.Render("enter send • esc cancel") + "\n")
+	default:
+		if m.status != "" {
+			footer.WriteString("\n" + m.status + "\n")
+		}
+		footer.WriteString(rewordDimStyle.Render("\n↑/↓ select • enter commit • e edit • c write your own • r regenerate • m more • f feedback • q cancel") + "\n")
+	}
+
+	lines, ends := m.suggestionLines()
+	if len(lines) == 0 {
+		lines = []string{"No suggestions, press r to regenerate or c to write your own", ""}
+	}
+
+	// Show the list from the top, scrolling just enough to keep the
+	// cursor's suggestion visible
+	available := m.height - strings.Count(header.String(), "\n") - strings.Count(footer.String(), "\n")
+	if m.height > 0 && available > 0 && len(lines) > available {
+		offset := 0
+		if m.cursor < len(ends) && ends[m.cursor] > available {
+			offset = ends[m.cursor] - available
+		}
+		lines = lines[offset : offset+available]
+	}
+
+	return header.String() + strings.Join(lines, "\n") + "\n" + footer.String()
+}
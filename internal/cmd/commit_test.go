@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ozankasikci/gitai/internal/config"
+	"github.com/ozankasikci/gitai/internal/logger"
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+
+	// Answer like Ollama does, with a single suggestion
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"response":          "1 - feat: add foo\nExplanation: Adds foo",
+			"prompt_eval_count": 120,
+			"eval_count":        8,
+		})
+	}))
+	defer server.Close()
+
+	// Point the config at the fake server
+	home, err := os.MkdirTemp("", "gitai-home-*")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(home)
+	os.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".config", "gitai")
+	content := fmt.Sprintf("llm:\n  provider: ollama\n  ollama:\n    url: %s\n    model: test-model\n", server.URL)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(content), 0644); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	server.Close()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
+// setupStagedRepo creates a repository with a staged file and changes to it
+func setupStagedRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Chdir(originalDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		require.NoError(t, exec.Command("git", args...).Run())
+	}
+	require.NoError(t, os.WriteFile("foo.txt", []byte("foo\n"), 0644))
+	require.NoError(t, exec.Command("git", "add", "foo.txt").Run())
+}
+
+// runGitai runs gitai with args like main does and returns what it wrote to
+// stdout, including output of the logger and pterm
+func runGitai(t *testing.T, args ...string) string {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	logger.Info.SetOutput(writer)
+	pterm.SetDefaultOutput(writer)
+	defer func() {
+		os.Stdout = stdout
+		logger.InitDefault()
+		pterm.SetDefaultOutput(stdout)
+	}()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		output <- string(data)
+	}()
+
+	require.NoError(t, config.InitWithoutSetup())
+	PrintBanner()
+	RootCmd.SetArgs(args)
+	err = RootCmd.Execute()
+	writer.Close()
+	require.NoError(t, err)
+	return <-output
+}
+
+func TestCommitMessageOnlyPrintsOnlyTheMessage(t *testing.T) {
+	setupStagedRepo(t)
+	t.Cleanup(func() { commitFlags.messageOnly = false })
+
+	stdout := runGitai(t, "commit", "--message-only")
+	assert.Equal(t, "feat: add foo\n", stdout)
+
+	// Nothing was committed
+	assert.Error(t, exec.Command("git", "rev-parse", "HEAD").Run())
+}
+
+func TestCommitOutputJSONIsParseable(t *testing.T) {
+	setupStagedRepo(t)
+	t.Cleanup(func() { commitFlags.output = "" })
+
+	stdout := runGitai(t, "commit", "--output", "json")
+
+	var out suggestionsOutput
+	require.NoError(t, json.Unmarshal([]byte(stdout), &out), stdout)
+	assert.Equal(t, suggestionsOutput{
+		Provider:    "ollama",
+		Model:       "test-model",
+		Suggestions: []suggestionOutput{{Message: "feat: add foo", Explanation: "Adds foo"}},
+		Usage:       usageOutput{InputTokens: 120, OutputTokens: 8},
+		Files:       []fileOutput{{Path: "foo.txt", Status: "added"}},
+	}, out)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ozankasikci/gitai/internal/git"
+)
+
+// editorCommand runs the editor git would use on path. The editor setting
+// may contain arguments, so it is run through the shell like git does.
+func editorCommand(path string) *exec.Cmd {
+	editor := git.Editor()
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// writeMessageFile writes message followed by help comments to a temporary
+// file for editing
+func writeMessageFile(message string, comments ...string) (string, error) {
+	file, err := os.CreateTemp("", "gitai-message-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create message file: %w", err)
+	}
+	defer file.Close()
+
+	var builder strings.Builder
+	builder.WriteString(strings.TrimSpace(message) + "\n\n")
+	for _, comment := range comments {
+		builder.WriteString(strings.TrimRight("# "+comment, " ") + "\n")
+	}
+
+	if _, err := file.WriteString(builder.String()); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write message file: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// readMessageFile reads an edited message back, dropping comment lines, and
+// removes the file
+func readMessageFile(path string) (string, error) {
+	defer os.Remove(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message file: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
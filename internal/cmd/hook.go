@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ozankasikci/gitai/internal/config"
+	"github.com/ozankasikci/gitai/internal/git"
+	"github.com/ozankasikci/gitai/internal/llm"
+	"github.com/ozankasikci/gitai/internal/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+func NewHookCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Manage the prepare-commit-msg hook",
+		Long: `Install a prepare-commit-msg hook so that plain 'git commit' and IDE commit
+dialogs are pre-filled with gitai's top suggestion. The alternatives are added
+as comments. Merges, amends and commits made with -m are left untouched.`,
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the prepare-commit-msg hook",
+		Args:  cobra.NoArgs,
+		RunE:  runHookInstall,
+	}
+
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the prepare-commit-msg hook",
+		Args:  cobra.NoArgs,
+		RunE:  runHookUninstall,
+	}
+
+	runCmd := &cobra.Command{
+		Use:    "run <message-file> [source] [sha]",
+		Short:  "Fill in a commit message file (called by the hook)",
+		Hidden: true,
+		Args:   cobra.RangeArgs(1, 3),
+		RunE:   runHookRun,
+	}
+
+	cmd.AddCommand(installCmd, uninstallCmd, runCmd)
+	return cmd
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate gitai executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	path, err := git.InstallPrepareCommitMsgHook(executable)
+	if err != nil {
+		return err
+	}
+
+	pterm.Success.Printf("Installed prepare-commit-msg hook at %s\n", path)
+	return nil
+}
+
+func runHookUninstall(cmd *cobra.Command, args []string) error {
+	path, err := git.UninstallPrepareCommitMsgHook()
+	if err != nil {
+		return err
+	}
+
+	pterm.Success.Printf("Removed prepare-commit-msg hook from %s\n", path)
+	return nil
+}
+
+// runHookRun generates suggestions without any interaction and writes them to
+// the commit message file git passes to prepare-commit-msg
+func runHookRun(cmd *cobra.Command, args []string) error {
+	messageFile := args[0]
+	if len(args) > 1 && args[1] != "" && args[1] != "template" {
+		logger.Debugf("Skipping commit message generation for source %q", args[1])
+		return nil
+	}
+
+	if !config.Get().IsSetupDone() {
+		logger.Debugf("gitai is not configured, leaving the commit message untouched")
+		return nil
+	}
+
+	changes, err := git.GetStagedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to get staged changes: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	opts, err := contentOptions()
+	if err != nil {
+		return err
+	}
+
+	content, err := git.GetStagedContent(opts)
+	if err != nil {
+		return fmt.Errorf("failed to get staged content: %w", err)
+	}
+	warnRedactions(opts)
+
+	client, err := llm.NewLLMClient()
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	suggestions, err := client.GenerateCommitSuggestions(content)
+	if err != nil {
+		return fmt.Errorf("failed to generate commit suggestions: %w", err)
+	}
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	existing, err := os.ReadFile(messageFile)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message file: %w", err)
+	}
+
+	message := formatHookMessage(suggestions, string(existing))
+	if err := os.WriteFile(messageFile, []byte(message), 0644); err != nil {
+		return fmt.Errorf("failed to write commit message file: %w", err)
+	}
+
+	return nil
+}
+
+// formatHookMessage puts the top suggestion first and lists every suggestion
+// as comments, followed by the message file's existing content
+func formatHookMessage(suggestions []llm.CommitSuggestion, existing string) string {
+	var builder strings.Builder
+	builder.WriteString(suggestions[0].Message + "\n\n")
+
+	builder.WriteString("# gitai suggestions:\n")
+	for i, suggestion := range suggestions {
+		builder.WriteString(fmt.Sprintf("#   %d. %s\n", i+1, suggestion.Message))
+		if suggestion.Explanation != "" {
+			builder.WriteString(fmt.Sprintf("#      %s\n", suggestion.Explanation))
+		}
+	}
+
+	if existing != "" {
+		builder.WriteString("#\n")
+		builder.WriteString(existing)
+	}
+
+	return builder.String()
+}
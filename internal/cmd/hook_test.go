@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsHookCommand(t *testing.T) {
+	assert.True(t, IsHookCommand([]string{"hook", "run", "prepare-commit-msg"}))
+	assert.True(t, IsHookCommand([]string{"-C", "/tmp/repo", "hook", "run", "prepare-commit-msg"}))
+	assert.True(t, IsHookCommand([]string{"--directory=/tmp/repo", "hook", "install"}))
+	assert.False(t, IsHookCommand([]string{"commit", "--message-only"}))
+	assert.False(t, IsHookCommand([]string{"-C", "hook", "commit"}))
+	assert.False(t, IsHookCommand(nil))
+}
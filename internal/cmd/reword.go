@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ozankasikci/gitai/internal/git"
+	"github.com/ozankasikci/gitai/internal/llm"
+	"github.com/ozankasikci/gitai/internal/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rewordHeaderStyle  = lipgloss.NewStyle().Bold(true)
+	rewordCursorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	rewordDimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	rewordChangedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+)
+
+// rewordItem is one commit being reworded
+type rewordItem struct {
+	commit      git.RangeCommit
+	suggestions []llm.CommitSuggestion
+	loaded      bool
+	err         error
+	// message is the accepted message; empty keeps the original
+	message string
+}
+
+type rewordSuggestionsMsg struct {
+	index       int
+	suggestions []llm.CommitSuggestion
+	err         error
+}
+
+type rewordEditedMsg struct {
+	index   int
+	message string
+	err     error
+}
+
+type rewordModel struct {
+	items    []rewordItem
+	current  int
+	cursor   int
+	client   llm.CommitMessageGenerator
+	opts     git.ContentOptions
+	spinner  spinner.Model
+	loading  bool
+	done     bool
+	quitting bool
+	status   string
+}
+
+func initialRewordModel(commits []git.RangeCommit, client llm.CommitMessageGenerator, opts git.ContentOptions) rewordModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	items := make([]rewordItem, len(commits))
+	for i, commit := range commits {
+		items[i] = rewordItem{commit: commit}
+	}
+
+	return rewordModel{
+		items:   items,
+		client:  client,
+		opts:    opts,
+		spinner: s,
+		loading: true,
+	}
+}
+
+func (m rewordModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.generate(0))
+}
+
+// generate asks the LLM for messages for the commit at index from its own diff
+func (m rewordModel) generate(index int) tea.Cmd {
+	commit := m.items[index].commit
+	client, opts := m.client, m.opts
+	return func() tea.Msg {
+		content, err := git.GetCommitContent(commit.Hash, opts)
+		if err != nil {
+			return rewordSuggestionsMsg{index: index, err: err}
+		}
+		logger.Debugf("\n=== Content of %s ===\n%s\n", commit.ShortHash(), content)
+
+		suggestions, err := client.GenerateCommitSuggestions(content)
+		return rewordSuggestionsMsg{index: index, suggestions: suggestions, err: err}
+	}
+}
+
+// options are the messages offered for the current commit: its original
+// message followed by the suggestions
+func (m rewordModel) options() []string {
+	item := m.items[m.current]
+	options := []string{strings.TrimSpace(item.commit.Message)}
+	for _, suggestion := range item.suggestions {
+		options = append(options, suggestion.Message)
+	}
+	return options
+}
+
+// moveTo shows the commit at index, generating its suggestions if needed
+func (m rewordModel) moveTo(index int) (rewordModel, tea.Cmd) {
+	m.current = index
+	m.cursor = 0
+	m.status = ""
+	if m.items[index].loaded {
+		m.loading = false
+		return m, nil
+	}
+	m.loading = true
+	return m, tea.Batch(m.spinner.Tick, m.generate(index))
+}
+
+// accept records message for the current commit and moves to the next one
+func (m rewordModel) accept(message string) (tea.Model, tea.Cmd) {
+	if strings.TrimSpace(message) == strings.TrimSpace(m.items[m.current].commit.Message) {
+		message = ""
+	}
+	m.items[m.current].message = message
+
+	if m.current == len(m.items)-1 {
+		m.done = true
+		return m, tea.Quit
+	}
+	return m.moveTo(m.current + 1)
+}
+
+func (m rewordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if !m.loading {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case rewordSuggestionsMsg:
+		item := &m.items[msg.index]
+		item.loaded = true
+		item.suggestions = msg.suggestions
+		item.err = msg.err
+		if msg.err != nil {
+			logger.Errorf("Failed to generate messages for %s: %v", item.commit.ShortHash(), msg.err)
+		}
+		if msg.index == m.current {
+			m.loading = false
+			if len(item.suggestions) > 0 {
+				m.cursor = 1
+			}
+		}
+		return m, nil
+
+	case rewordEditedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		if msg.message == "" {
+			m.status = "Empty message, nothing changed"
+			return m, nil
+		}
+		return m.accept(msg.message)
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if m.loading {
+			return m, nil
+		}
+
+		options := m.options()
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(options)-1 {
+				m.cursor++
+			}
+		case "enter":
+			return m.accept(options[m.cursor])
+		case "e":
+			return m, m.edit(options[m.cursor])
+		case "r":
+			m.items[m.current].loaded = false
+			return m.moveTo(m.current)
+		case "left", "b":
+			if m.current > 0 {
+				return m.moveTo(m.current - 1)
+			}
+		}
+	}
+	return m, nil
+}
+
+// edit opens message in the editor and accepts the result
+func (m rewordModel) edit(message string) tea.Cmd {
+	index := m.current
+	commit := m.items[index].commit
+	path, err := writeMessageFile(message,
+		fmt.Sprintf("Rewording %s %s", commit.ShortHash(), commit.Subject()),
+		"Lines starting with '#' are ignored. An empty message changes nothing.")
+	if err != nil {
+		return func() tea.Msg { return rewordEditedMsg{index: index, err: err} }
+	}
+
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		if err != nil {
+			return rewordEditedMsg{index: index, err: fmt.Errorf("editor failed: %w", err)}
+		}
+		edited, err := readMessageFile(path)
+		return rewordEditedMsg{index: index, message: edited, err: err}
+	})
+}
+
+func (m rewordModel) View() string {
+	if m.done || m.quitting {
+		return ""
+	}
+
+	item := m.items[m.current]
+	var s strings.Builder
+	s.WriteString(rewordHeaderStyle.Render(fmt.Sprintf("Commit %d/%d: %s %s", m.current+1, len(m.items), item.commit.ShortHash(), item.commit.Subject())))
+	s.WriteString("\n" + rewordDimStyle.Render("Author: "+item.commit.Author) + "\n\n")
+
+	if m.loading {
+		s.WriteString(fmt.Sprintf("%s Generating commit suggestions...\n", m.spinner.View()))
+		return s.String()
+	}
+
+	if item.err != nil {
+		s.WriteString(fmt.Sprintf("Failed to generate suggestions: %v\n\n", item.err))
+	}
+
+	for i, option := range m.options() {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = rewordCursorStyle.Render("> ")
+		}
+
+		if i == 0 {
+			s.WriteString(fmt.Sprintf("%s%s %s\n", cursor, rewordDimStyle.Render("[keep]"), firstLine(option)))
+			continue
+		}
+		s.WriteString(fmt.Sprintf("%s%d. %s\n", cursor, i, option))
+		if explanation := item.suggestions[i-1].Explanation; explanation != "" {
+			s.WriteString("     " + rewordDimStyle.Render(explanation) + "\n")
+		}
+	}
+
+	if m.status != "" {
+		s.WriteString("\n" + m.status + "\n")
+	}
+	s.WriteString(rewordDimStyle.Render("\n↑/↓ select • enter accept • e edit • r regenerate • b back • q abort") + "\n")
+	return s.String()
+}
+
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return line
+}
+
+func NewRewordCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reword <range>",
+		Short: "Rewrite the messages of existing commits",
+		Long: `Generate better messages for the commits in a range ending at HEAD, such as
+main..HEAD, and review them one by one. Accepted messages are written by
+rewriting the branch; trees and authors are kept. The previous branch tip is
+saved as ORIG_HEAD, so 'git reset --hard ORIG_HEAD' undoes the rewrite.`,
+		Example: `  gitai reword main..HEAD
+  gitai reword HEAD~3`,
+		Args: cobra.ExactArgs(1),
+		RunE: runReword,
+	}
+	return cmd
+}
+
+func runReword(cmd *cobra.Command, args []string) error {
+	commits, err := git.GetRangeCommits(args[0])
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		fmt.Println("No commits to reword")
+		return nil
+	}
+
+	opts, err := contentOptions()
+	if err != nil {
+		return err
+	}
+
+	client, err := llm.NewLLMClient()
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	p := tea.NewProgram(initialRewordModel(commits, client, opts))
+	model, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("error running program: %w", err)
+	}
+	warnRedactions(opts)
+
+	m := model.(rewordModel)
+	if m.quitting {
+		fmt.Println("Reword cancelled, no commits changed")
+		return nil
+	}
+
+	messages := make(map[string]string)
+	for _, item := range m.items {
+		if item.message == "" {
+			continue
+		}
+		messages[item.commit.Hash] = item.message
+		fmt.Printf("%s %s\n  %s\n", item.commit.ShortHash(), item.commit.Subject(), rewordChangedStyle.Render("→ "+firstLine(item.message)))
+	}
+
+	if len(messages) == 0 {
+		fmt.Println("No commit messages changed")
+		return nil
+	}
+
+	tip, err := git.RewordCommits(commits, messages, commitOptions())
+	if err != nil {
+		return fmt.Errorf("failed to reword commits: %w", err)
+	}
+
+	pterm.Success.Printf("Reworded %d commit(s), HEAD is now %s. Undo with 'git reset --hard ORIG_HEAD'\n", len(messages), tip[:7])
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 
 	"github.com/ozankasikci/gitai/internal/config"
+	"github.com/ozankasikci/gitai/internal/git"
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +17,13 @@ var RootCmd = &cobra.Command{
 	Long: `GitAI is a command-line tool that uses AI to help with Git operations.
 Currently supports generating commit messages based on staged changes.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Run as if started in the -C directory, like git -C
+		if workDir != "" {
+			if err := os.Chdir(workDir); err != nil {
+				return fmt.Errorf("cannot change to '%s': %w", workDir, err)
+			}
+		}
+
 		// Skip config initialization for config commands
 		if cmd.Parent() != nil && cmd.Parent().Name() == "config" {
 			return nil
@@ -24,20 +33,64 @@ Currently supports generating commit messages based on staged changes.`,
 		if err := config.Init(); err != nil {
 			return fmt.Errorf("failed to initialize config: %w", err)
 		}
+
+		git.PreferGitBinary = config.Get().Git.PreferBinary
 		return nil
 	},
 }
 
+// workDir is the directory given with -C
+var workDir string
+
 func init() {
+	RootCmd.PersistentFlags().StringVarP(&workDir, "directory", "C", "", "Run as if gitai was started in this directory")
+
 	// Add all subcommands here
 	RootCmd.AddCommand(
 		NewAddCommand(),
 		NewCommitCommand(),
 		NewAutoCommand(),
 		NewConfigCommand(),
+		NewHookCommand(),
+		NewRewordCommand(),
+		NewSplitCommand(),
 	)
 }
 
+// IsHookCommand reports whether args run a hook subcommand, looking past
+// persistent flags such as -C <dir>
+func IsHookCommand(args []string) bool {
+	found, _, err := RootCmd.Find(args)
+	if err != nil {
+		return false
+	}
+	for c := found; c != nil; c = c.Parent() {
+		if c.Name() == "hook" && c.Parent() == RootCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintBanner shows the configured provider and model. It goes to stderr so
+// that stdout only holds what --message-only and --output print.
+func PrintBanner() {
+	provider, model := config.Get().GetProviderAndModel()
+
+	// Create table data
+	tableData := pterm.TableData{
+		{"Provider", provider},
+		{"Model", model},
+	}
+
+	// Render table
+	_ = pterm.DefaultTable.
+		WithData(tableData).
+		WithBoxed(true).
+		WithWriter(os.Stderr).
+		Render()
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ozankasikci/gitai/internal/git"
+	"github.com/ozankasikci/gitai/internal/llm"
+	"github.com/ozankasikci/gitai/internal/logger"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+func NewSplitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split",
+		Short: "Split staged changes into logical commits",
+		Long: `Ask the AI to group the staged files and hunks into coherent logical
+changes, each with its own message. After you confirm the plan, every group is
+staged in turn and committed. Changes left out of the plan stay staged.`,
+		Args: cobra.NoArgs,
+		RunE: runSplit,
+	}
+	addCommitFlags(cmd)
+	return cmd
+}
+
+func runSplit(cmd *cobra.Command, args []string) error {
+	opts, err := contentOptions()
+	if err != nil {
+		return err
+	}
+
+	units, content, err := git.GetSplitUnits(opts)
+	if err != nil {
+		return fmt.Errorf("failed to get staged changes: %w", err)
+	}
+	if len(units) == 0 {
+		return fmt.Errorf("no staged changes found. Use 'git add' to stage changes")
+	}
+	if len(units) == 1 {
+		fmt.Println("Only one change is staged, nothing to split. Use 'gitai commit' instead")
+		return nil
+	}
+	warnRedactions(opts)
+	logger.Debugf("\n=== Split units ===\n%s\n", content)
+
+	client, err := llm.NewLLMClient()
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	spinner, _ := pterm.DefaultSpinner.Start("Planning commits...")
+	groups, err := client.GenerateCommitGroups(content)
+	if err != nil {
+		spinner.Fail("Failed to plan commits")
+		return fmt.Errorf("failed to generate commit groups: %w", err)
+	}
+	spinner.Stop()
+
+	groups, unassigned := validateGroups(groups, units)
+	if len(groups) == 0 {
+		return fmt.Errorf("the AI did not propose any usable commits")
+	}
+
+	printSplitPlan(groups, units, unassigned)
+
+	fmt.Printf("\nCreate these %d commits? [y/N]: ", len(groups))
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return fmt.Errorf("failed to read input")
+	}
+	if answer := strings.ToLower(strings.TrimSpace(scanner.Text())); answer != "y" && answer != "yes" {
+		fmt.Println("Split cancelled")
+		return nil
+	}
+
+	allIDs := make([]int, len(units))
+	for i, unit := range units {
+		allIDs[i] = unit.ID
+	}
+
+	var staged []int
+	for i, group := range groups {
+		staged = append(staged, group.Units...)
+		if err := git.StageSplitUnits(units, staged); err != nil {
+			return restoreAfterSplitError(units, allIDs, fmt.Errorf("failed to stage commit %d: %w", i+1, err))
+		}
+		if err := git.CommitChanges(group.Message, commitOptions()); err != nil {
+			return restoreAfterSplitError(units, allIDs, fmt.Errorf("failed to create commit %d: %w", i+1, err))
+		}
+		pterm.Success.Printf("Committed %s\n", group.Message)
+	}
+
+	// Leaves whatever the plan did not cover staged
+	if err := git.StageSplitUnits(units, allIDs); err != nil {
+		return fmt.Errorf("failed to restage remaining changes: %w", err)
+	}
+	if len(unassigned) > 0 {
+		pterm.Info.Printf("%d change(s) were not part of the plan and remain staged\n", len(unassigned))
+	}
+
+	return nil
+}
+
+// validateGroups drops unknown and repeated unit IDs and empty groups, and
+// returns the units no group claimed
+func validateGroups(groups []llm.CommitGroup, units []git.SplitUnit) ([]llm.CommitGroup, []int) {
+	claimed := make(map[int]bool)
+	var valid []llm.CommitGroup
+	for _, group := range groups {
+		var ids []int
+		for _, id := range group.Units {
+			if id < 1 || id > len(units) || claimed[id] {
+				logger.Debugf("Ignoring unit %d in group %q", id, group.Message)
+				continue
+			}
+			claimed[id] = true
+			ids = append(ids, id)
+		}
+		if len(ids) == 0 || group.Message == "" {
+			continue
+		}
+		group.Units = ids
+		valid = append(valid, group)
+	}
+
+	var unassigned []int
+	for _, unit := range units {
+		if !claimed[unit.ID] {
+			unassigned = append(unassigned, unit.ID)
+		}
+	}
+	return valid, unassigned
+}
+
+func printSplitPlan(groups []llm.CommitGroup, units []git.SplitUnit, unassigned []int) {
+	fmt.Println("\nProposed commits:")
+	for i, group := range groups {
+		fmt.Printf("\n%d. %s\n", i+1, group.Message)
+		if group.Explanation != "" {
+			pterm.FgGray.Println("   " + group.Explanation)
+		}
+		for _, id := range group.Units {
+			fmt.Printf("   - %s\n", units[id-1])
+		}
+	}
+
+	if len(unassigned) > 0 {
+		fmt.Println("\nLeft staged:")
+		for _, id := range unassigned {
+			fmt.Printf("   - %s\n", units[id-1])
+		}
+	}
+}
+
+// restoreAfterSplitError puts the remaining changes back in the staging area
+// so nothing is lost when a commit fails midway
+func restoreAfterSplitError(units []git.SplitUnit, allIDs []int, err error) error {
+	if restoreErr := git.StageSplitUnits(units, allIDs); restoreErr != nil {
+		logger.Errorf("Failed to restore the staging area: %v", restoreErr)
+	}
+	return err
+}
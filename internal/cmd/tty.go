@@ -0,0 +1,13 @@
+package cmd
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// isInteractive reports whether stdin and stdout are terminals, so that
+// prompts and full-screen views can be shown
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
@@ -32,6 +32,31 @@ type Config struct {
 		Level   string
 		Verbose bool
 	}
+	Commit struct {
+		// Signoff adds a Signed-off-by trailer to every commit
+		Signoff bool
+		// Trailers are "Key: value" trailers appended to every commit
+		Trailers []string
+		// SigningKeyFile is an armored OpenPGP private key used to sign
+		// commits without calling gpg
+		SigningKeyFile string
+	}
+	Git struct {
+		// PreferBinary uses the git binary, when available, for diffs and
+		// unstaging instead of go-git
+		PreferBinary bool
+	}
+	Prompt struct {
+		// MaxFileSize is the largest diff in bytes sent to the provider;
+		// larger changes are described by metadata only
+		MaxFileSize int64
+	}
+	Redaction struct {
+		// Disabled turns off secret redaction of diffs sent to the provider
+		Disabled bool
+		// Patterns are additional regular expressions to redact
+		Patterns []string
+	}
 }
 
 var cfg *Config
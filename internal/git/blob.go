@@ -0,0 +1,228 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// DefaultMaxFileSize is the largest diff, in bytes, included in the prompt
+// when ContentOptions.MaxFileSize is not set
+const DefaultMaxFileSize = 100 * 1024
+
+// sniffLength is how much of a blob is inspected to detect binary content,
+// matching git's own heuristic
+const sniffLength = 8000
+
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1"
+
+// blobInfo describes a file version without its content
+type blobInfo struct {
+	Size   int64
+	Type   string
+	Binary bool
+	// LFS is set for Git LFS pointers, in which case Size is the size of
+	// the object the pointer refers to
+	LFS    bool
+	Width  int
+	Height int
+}
+
+func (b *blobInfo) dimensions() string {
+	if b.Width == 0 || b.Height == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", b.Width, b.Height)
+}
+
+// inspectBlob reads the metadata of the blob with the given hash
+func inspectBlob(repo *git.Repository, hash plumbing.Hash) (*blobInfo, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	info := &blobInfo{
+		Size:   blob.Size,
+		Type:   http.DetectContentType(head),
+		Binary: isBinaryContent(head),
+	}
+
+	if bytes.HasPrefix(head, []byte(lfsPointerPrefix)) {
+		info.LFS = true
+		info.Type = "Git LFS object"
+		info.Size = parseLFSSize(head)
+		return info, nil
+	}
+
+	if strings.HasPrefix(info.Type, "image/") {
+		// Decoding the image header may need more than the sniffed bytes
+		full, err := blob.Reader()
+		if err == nil {
+			if cfg, _, err := image.DecodeConfig(full); err == nil {
+				info.Width, info.Height = cfg.Width, cfg.Height
+			}
+			full.Close()
+		}
+		info.Binary = true
+	}
+
+	return info, nil
+}
+
+// isBinaryContent applies git's heuristic: content with a NUL byte in its
+// first sniffLength bytes is binary
+func isBinaryContent(content []byte) bool {
+	if len(content) > sniffLength {
+		content = content[:sniffLength]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+func parseLFSSize(pointer []byte) int64 {
+	scanner := bufio.NewScanner(bytes.NewReader(pointer))
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "size "); ok {
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				return size
+			}
+		}
+	}
+	return 0
+}
+
+// stagedBlobInfo returns the metadata of path in the index and of its version
+// in tree, read from oldPath for renames and copies. Either is nil when the
+// file does not exist on that side.
+func stagedBlobInfo(repo *git.Repository, tree *object.Tree, oldPath, path string) (before, after *blobInfo, err error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if entry, err := idx.Entry(path); err == nil {
+		after, err = inspectBlob(repo, entry.Hash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read staged blob: %w", err)
+		}
+	}
+
+	if tree == nil {
+		// No commits yet, so nothing to compare against
+		return nil, after, nil
+	}
+
+	if oldPath == "" {
+		oldPath = path
+	}
+	if entry, err := tree.FindEntry(oldPath); err == nil {
+		before, err = inspectBlob(repo, entry.Hash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read committed blob: %w", err)
+		}
+	}
+
+	return before, after, nil
+}
+
+// describeBlobChange summarizes a binary or oversized change using metadata
+// only: type, size, image dimensions and old → new size for modifications
+func describeBlobChange(before, after *blobInfo, reason string) string {
+	current := after
+	if current == nil {
+		current = before
+	}
+
+	kind := "Binary file"
+	switch {
+	case current.LFS:
+		kind = "Git LFS object"
+	case !current.Binary:
+		kind = "Large file"
+	}
+
+	// LFS pointers are typed by their kind already
+	var details []string
+	if current.Type != kind {
+		details = append(details, current.Type)
+	}
+	switch {
+	case before == nil:
+		details = append(details, formatSize(after.Size))
+		if dims := after.dimensions(); dims != "" {
+			details = append(details, dims)
+		}
+		return fmt.Sprintf("%s added (%s), %s\n", kind, strings.Join(details, ", "), reason)
+	case after == nil:
+		details = append(details, formatSize(before.Size))
+		return fmt.Sprintf("%s deleted (%s), %s\n", kind, strings.Join(details, ", "), reason)
+	}
+
+	details = append(details, fmt.Sprintf("%s → %s", formatSize(before.Size), formatSize(after.Size)))
+	if before.dimensions() != after.dimensions() {
+		details = append(details, fmt.Sprintf("%s → %s", orUnknown(before.dimensions()), orUnknown(after.dimensions())))
+	} else if dims := after.dimensions(); dims != "" {
+		details = append(details, dims)
+	}
+	return fmt.Sprintf("%s modified (%s), %s\n", kind, strings.Join(details, ", "), reason)
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// isBinaryChange reports whether either side of a change should be described
+// by metadata instead of content
+func isBinaryChange(before, after *blobInfo) bool {
+	for _, info := range []*blobInfo{before, after} {
+		if info != nil && (info.Binary || info.LFS) {
+			logger.Debugf("Detected non-text content: %s", info.Type)
+			return true
+		}
+	}
+	return false
+}
@@ -3,19 +3,27 @@ package git
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/ozankasikci/gitai/internal/logger"
+	"github.com/ozankasikci/gitai/internal/redact"
 )
 
 type StagedChange struct {
-	Path       string
-	Status     string
+	Path string
+	// OldPath is the source of a renamed or copied file
+	OldPath string
+	Status  string
+	// Similarity is the percentage of content shared with OldPath
+	Similarity int
 	FileType   string
 	Content    string
 	Summary    string
@@ -24,14 +32,49 @@ type StagedChange struct {
 }
 
 type FileChange struct {
-	Path   string
-	Status string
-	Staged bool
+	Path string
+	// OldPath is the source of a staged rename or copy
+	OldPath string
+	Status  string
+	Staged  bool
+	// Index and Worktree are the two columns of git status -s: Index compares
+	// the index with HEAD and Worktree the worktree with the index. ' ' means
+	// unchanged and '?' untracked.
+	Index    byte
+	Worktree byte
+}
+
+// ShortStatus returns the two-letter status shown by git status -s
+func (c FileChange) ShortStatus() string {
+	return string([]byte{c.Index, c.Worktree})
+}
+
+// HasUnstagedChanges reports whether the worktree differs from the index,
+// such as when a file was edited after staging or only partly staged
+func (c FileChange) HasUnstagedChanges() bool {
+	return c.Worktree != ' '
+}
+
+// DisplayPath returns "old → new" for renames and copies, or the path
+func (c StagedChange) DisplayPath() string {
+	return displayPath(c.OldPath, c.Path)
+}
+
+// DisplayPath returns "old → new" for renames and copies, or the path
+func (c FileChange) DisplayPath() string {
+	return displayPath(c.OldPath, c.Path)
+}
+
+func displayPath(oldPath, path string) string {
+	if oldPath == "" {
+		return path
+	}
+	return fmt.Sprintf("%s → %s", oldPath, path)
 }
 
 // GetStagedChanges returns a list of files that are staged for commit
 func GetStagedChanges() ([]StagedChange, error) {
-	repo, err := git.PlainOpen(".")
+	repo, err := openRepository()
 	if err != nil {
 		logger.Errorf("Failed to open git repository: %v", err)
 		return nil, fmt.Errorf("failed to open git repository: %w", err)
@@ -51,8 +94,27 @@ func GetStagedChanges() ([]StagedChange, error) {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
+	tree, err := headTree(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+
+	renames, err := detectRenames(repo, tree, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect renames: %w", err)
+	}
+	renamedFrom := make(map[string]bool)
+	for _, pair := range renames {
+		if pair.Status == "renamed" {
+			renamedFrom[pair.OldPath] = true
+		}
+	}
+
 	var changes []StagedChange
 	for path, fileStatus := range status {
+		if renamedFrom[path] {
+			continue
+		}
 		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked ||
 			fileStatus.Worktree == git.Deleted {
 			logger.Debugf("Found staged file: %s (status: %s)", path, statusToString(fileStatus.Staging))
@@ -60,6 +122,11 @@ func GetStagedChanges() ([]StagedChange, error) {
 				Path:   path,
 				Status: statusToString(fileStatus.Staging),
 			}
+			if pair, ok := renames[path]; ok {
+				change.OldPath = pair.OldPath
+				change.Status = pair.Status
+				change.Similarity = pair.Similarity
+			}
 			if fileStatus.Worktree == git.Deleted {
 				change.Status = "deleted"
 			}
@@ -67,9 +134,122 @@ func GetStagedChanges() ([]StagedChange, error) {
 		}
 	}
 
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+// GetAmendChanges returns the changes of the HEAD commit combined with the
+// staged changes, i.e. what HEAD would contain after amending
+func GetAmendChanges() ([]StagedChange, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	base, err := amendBase(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := indexStatus(repo, base.tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare the index with %s: %w", base.rev, err)
+	}
+
+	renames, err := detectRenames(repo, base.tree, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect renames: %w", err)
+	}
+	renamedFrom := make(map[string]bool)
+	for _, pair := range renames {
+		if pair.Status == "renamed" {
+			renamedFrom[pair.OldPath] = true
+		}
+	}
+
+	var changes []StagedChange
+	for path, fileStatus := range status {
+		if renamedFrom[path] {
+			continue
+		}
+		change := StagedChange{
+			Path:   path,
+			Status: statusToString(fileStatus.Staging),
+		}
+		if pair, ok := renames[path]; ok {
+			change.OldPath = pair.OldPath
+			change.Status = pair.Status
+			change.Similarity = pair.Similarity
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
 	return changes, nil
 }
 
+// indexStatus compares the index with tree, which may be nil for an empty
+// base. Only the staging side of the returned status is set.
+func indexStatus(repo *git.Repository, tree *object.Tree) (git.Status, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	committed := make(map[string]*object.File)
+	if tree != nil {
+		err = tree.Files().ForEach(func(f *object.File) error {
+			committed[f.Name] = f
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	status := make(git.Status)
+	for _, entry := range idx.Entries {
+		file, ok := committed[entry.Name]
+		delete(committed, entry.Name)
+		switch {
+		case !ok:
+			status[entry.Name] = &git.FileStatus{Staging: git.Added, Worktree: git.Unmodified}
+		case file.Hash != entry.Hash || file.Mode != entry.Mode:
+			status[entry.Name] = &git.FileStatus{Staging: git.Modified, Worktree: git.Unmodified}
+		}
+	}
+	for path := range committed {
+		status[path] = &git.FileStatus{Staging: git.Deleted, Worktree: git.Unmodified}
+	}
+
+	return status, nil
+}
+
+// HeadCommitMessage returns the message of the HEAD commit
+func HeadCommitMessage() (string, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	return commit.Message, nil
+}
+
 // statusToString converts git status code to string
 func statusToString(status git.StatusCode) string {
 	switch status {
@@ -95,44 +275,74 @@ func statusToString(status git.StatusCode) string {
 func FormatChangesForPrompt(changes []StagedChange) string {
 	var builder strings.Builder
 	for _, change := range changes {
-		builder.WriteString(fmt.Sprintf("%s (%s)\n", change.Path, change.Status))
+		builder.WriteString(fmt.Sprintf("%s (%s)\n", change.DisplayPath(), change.Status))
 	}
 	return builder.String()
 }
 
+// ContentOptions controls how staged content is prepared for the prompt
+type ContentOptions struct {
+	// Redactor, when set, replaces secrets in each file's diff
+	Redactor *redact.Redactor
+	// MaxFileSize is the largest diff in bytes included in the prompt.
+	// Larger changes are described by metadata only. Defaults to
+	// DefaultMaxFileSize.
+	MaxFileSize int64
+	// Amend describes the HEAD commit's changes together with the staged
+	// ones and includes HEAD's message as context
+	Amend bool
+}
+
 // GetStagedContent returns a summary of the staged changes
-func GetStagedContent() (string, error) {
-	changes, err := GetStagedChanges()
+func GetStagedContent(opts ContentOptions) (string, error) {
+	var changes []StagedChange
+	var err error
+	if opts.Amend {
+		changes, err = GetAmendChanges()
+	} else {
+		changes, err = GetStagedChanges()
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to get staged changes: %w", err)
 	}
 
 	var content strings.Builder
 
-	// First add the summary of changes
-	content.WriteString("=== Changes Summary ===\n\n")
-	for _, change := range changes {
-		content.WriteString(fmt.Sprintf("%s (status: %s)\n", change.Path, change.Status))
+	if opts.Amend {
+		message, err := HeadCommitMessage()
+		if err != nil {
+			return "", err
+		}
+		content.WriteString("=== Current Commit Message ===\n\n")
+		content.WriteString(strings.TrimSpace(message) + "\n\n")
 	}
 
+	writeChangesSummary(&content, changes)
+
 	// Then add the actual diff content
-	repo, err := git.PlainOpen(".")
+	repo, err := openRepository()
 	if err != nil {
 		return "", fmt.Errorf("failed to open git repository: %w", err)
 	}
 
-	w, err := repo.Worktree()
-	if err != nil {
-		return "", fmt.Errorf("failed to get worktree: %w", err)
+	var base *diffBase
+	if opts.Amend {
+		base, err = amendBase(repo)
+	} else {
+		base, err = headBase(repo)
 	}
-
-	_, err = w.Status()
 	if err != nil {
-		return "", fmt.Errorf("failed to get status: %w", err)
+		return "", err
 	}
 
 	content.WriteString("\n=== Detailed Changes ===\n")
 
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	ignore := loadIgnoreMatcher(root)
+
 	// Get the actual diff for each staged file
 	for _, change := range changes {
 		if change.Status == "deleted" {
@@ -140,19 +350,104 @@ func GetStagedContent() (string, error) {
 			continue
 		}
 
-		diff, err := getDiffForFile(repo, change.Path)
+		if isIgnored(ignore, change.Path) {
+			logger.Debugf("Omitting content of %s matched by %s", change.Path, IgnoreFileName)
+			content.WriteString(fmt.Sprintf("\n=== %s ===\n(changed, content omitted)\n", change.Path))
+			continue
+		}
+
+		before, after, err := stagedBlobInfo(repo, base.tree, change.OldPath, change.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to inspect %s: %w", change.Path, err)
+		}
+
+		err = writeChangeDetails(&content, change, before, after, opts, func() (string, error) {
+			return getDiffForFile(repo, base, change.OldPath, change.Path)
+		})
 		if err != nil {
-			return "", fmt.Errorf("failed to get diff for %s: %w", change.Path, err)
+			return "", err
 		}
-		content.WriteString(fmt.Sprintf("\n=== %s ===\n%s\n", change.Path, diff))
 	}
 
 	return content.String(), nil
 }
 
-func getDiffForFile(repo *git.Repository, path string) (string, error) {
-	// Use git command to get diff since go-git's diff functionality is limited
-	cmd := exec.Command("git", "diff", "--cached", "--", path)
+// writeChangesSummary writes the list of changed files that heads the prompt
+// content
+func writeChangesSummary(content *strings.Builder, changes []StagedChange) {
+	content.WriteString("=== Changes Summary ===\n\n")
+	for _, change := range changes {
+		if change.OldPath != "" {
+			content.WriteString(fmt.Sprintf("%s (status: %s, %d%% similar)\n", change.DisplayPath(), change.Status, change.Similarity))
+			continue
+		}
+		content.WriteString(fmt.Sprintf("%s (status: %s)\n", change.Path, change.Status))
+	}
+}
+
+// writeChangeDetails writes the section of a changed file
+func writeChangeDetails(content *strings.Builder, change StagedChange, before, after *blobInfo, opts ContentOptions, getDiff func() (string, error)) error {
+	title, body, err := changeDetails(change, before, after, opts, getDiff)
+	if err != nil {
+		return err
+	}
+	content.WriteString(fmt.Sprintf("\n=== %s ===\n%s", title, body))
+	return nil
+}
+
+// changeDetails returns the title and body of a changed file's section.
+// Binary and oversized changes are described by their metadata in before and
+// after; otherwise the diff is generated and redacted.
+func changeDetails(change StagedChange, before, after *blobInfo, opts ContentOptions, getDiff func() (string, error)) (string, string, error) {
+	maxFileSize := opts.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = DefaultMaxFileSize
+	}
+
+	if isBinaryChange(before, after) {
+		return change.DisplayPath(), describeBlobChange(before, after, "content omitted"), nil
+	}
+
+	if before == nil && after != nil && after.Size > maxFileSize {
+		return change.Path, describeBlobChange(before, after, "content omitted"), nil
+	}
+
+	if change.Similarity == 100 {
+		return change.DisplayPath(), fmt.Sprintf("(%s without changes)\n", change.Status), nil
+	}
+
+	diff, err := getDiff()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get diff for %s: %w", change.Path, err)
+	}
+
+	if int64(len(diff)) > maxFileSize && after != nil {
+		reason := fmt.Sprintf("diff omitted (%s)", formatSize(int64(len(diff))))
+		return change.DisplayPath(), describeBlobChange(before, after, reason), nil
+	}
+	if opts.Redactor != nil {
+		diff = opts.Redactor.Redact(change.Path, diff)
+	}
+	return change.DisplayPath(), diff + "\n", nil
+}
+
+func getDiffForFile(repo *git.Repository, base *diffBase, oldPath, path string) (string, error) {
+	if !useGitBinary() {
+		return stagedDiff(repo, base.tree, oldPath, path)
+	}
+
+	args := []string{"diff", "--cached", "--", path}
+	if base.rev != "" {
+		args = []string{"diff", "--cached", base.rev, "--", path}
+	}
+	if oldPath != "" {
+		// Compare the rename or copy source in the base with the staged file
+		args = []string{"diff", base.rev + ":" + oldPath, ":" + path}
+	}
+	cmd, err := gitCommand(repo, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get diff for %s: %w", path, err)
@@ -160,7 +455,10 @@ func getDiffForFile(repo *git.Repository, path string) (string, error) {
 
 	if len(output) == 0 {
 		// If no diff (e.g., for newly added files), get the entire content
-		cmd = exec.Command("git", "show", ":"+path)
+		cmd, err = gitCommand(repo, "show", ":"+path)
+		if err != nil {
+			return "", fmt.Errorf("failed to get worktree: %w", err)
+		}
 		output, err = cmd.Output()
 		if err != nil {
 			return "", fmt.Errorf("failed to get content for %s: %w", path, err)
@@ -170,9 +468,36 @@ func getDiffForFile(repo *git.Repository, path string) (string, error) {
 	return string(output), nil
 }
 
-// CommitChanges commits the staged changes with the given message
-func CommitChanges(message string) error {
-	repo, err := git.PlainOpen(".")
+// CommitOptions controls the trailers appended to a commit message, how the
+// commit is signed and whether hooks are verified
+type CommitOptions struct {
+	// Signoff adds a Signed-off-by trailer for the committer (DCO)
+	Signoff bool
+	// CoAuthors are identities added as Co-authored-by trailers. Partial
+	// names or emails are resolved against the repository's known authors.
+	CoAuthors []string
+	// Trailers are arbitrary "Key: value" trailers
+	Trailers []string
+	// Sign forces a signed commit even when commit.gpgsign is not set
+	Sign bool
+	// NoSign disables signing even when commit.gpgsign is set
+	NoSign bool
+	// SigningKeyFile is an armored OpenPGP private key used to sign
+	// in-process instead of delegating to gpg.program
+	SigningKeyFile string
+	// NoVerify skips the pre-commit and commit-msg hooks
+	NoVerify bool
+	// Amend replaces the HEAD commit instead of creating a new one
+	Amend bool
+	// ResetAuthor makes the committer the author of an amended commit and
+	// resets the author date. By default both are kept.
+	ResetAuthor bool
+}
+
+// CommitChanges commits the staged changes with the given message. With
+// opts.Amend the HEAD commit is rewritten instead.
+func CommitChanges(message string, opts CommitOptions) error {
+	repo, err := openRepository()
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
@@ -191,24 +516,181 @@ func CommitChanges(message string) error {
 		return fmt.Errorf("git user.name and user.email must be set")
 	}
 
-	_, err = worktree.Commit(message, &git.CommitOptions{
-		Author: &object.Signature{
-			Name:  config.Name,
-			Email: config.Email,
-			When:  time.Now(),
-		},
-	})
+	var amended *object.Commit
+	if opts.Amend {
+		amended, err = amendedCommit(repo)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !opts.NoVerify {
+		if err := runHook(repo, "pre-commit"); err != nil {
+			return err
+		}
+	}
+
+	message, err = buildCommitMessage(message, config, opts)
+	if err != nil {
+		return err
+	}
+
+	message, err = runMessageHooks(repo, message, opts.NoVerify)
+	if err != nil {
+		return err
+	}
+
+	committer := &object.Signature{
+		Name:  config.Name,
+		Email: config.Email,
+		When:  time.Now(),
+	}
+	commitOpts := &git.CommitOptions{
+		Author:    committer,
+		Committer: committer,
+		Amend:     opts.Amend,
+	}
+	if amended != nil && !opts.ResetAuthor {
+		author := amended.Author
+		commitOpts.Author = &author
+	}
+
+	// Honor commit.gpgsign unless overridden on the command line
+	signing := readSigningConfig(repo)
+	if (signing.Enabled || opts.Sign) && !opts.NoSign {
+		signer, err := newSigner(signing, config, opts.SigningKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to set up commit signing: %w", err)
+		}
+		logger.Debugf("Signing commit using %s format", signing.Format)
+		commitOpts.Signer = signer
+	}
+
+	_, err = worktree.Commit(message, commitOpts)
 
 	if err != nil {
 		return fmt.Errorf("failed to commit changes: %w", err)
 	}
 
+	// post-commit cannot affect the outcome of the commit
+	if err := runHook(repo, "post-commit"); err != nil {
+		logger.Errorf("%v", err)
+	}
+
 	return nil
 }
 
+// amendedCommit returns the HEAD commit to be amended. Merge commits are
+// refused because amending keeps only the first parent.
+func amendedCommit(repo *git.Repository) (*object.Commit, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("nothing to amend: %w", err)
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	if commit.NumParents() > 1 {
+		return nil, fmt.Errorf("amending merge commits is not supported")
+	}
+	return commit, nil
+}
+
+// runMessageHooks writes message to COMMIT_EDITMSG and passes it through the
+// prepare-commit-msg and commit-msg hooks, returning the message they leave.
+// commit-msg is skipped when noVerify is set.
+func runMessageHooks(repo *git.Repository, message string, noVerify bool) (string, error) {
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	gitDir, err := worktreeGitDir(root)
+	if err != nil {
+		return "", err
+	}
+
+	messageFile := filepath.Join(gitDir, "COMMIT_EDITMSG")
+	if err := os.WriteFile(messageFile, []byte(message), 0644); err != nil {
+		return "", fmt.Errorf("failed to write commit message: %w", err)
+	}
+
+	if err := runHook(repo, "prepare-commit-msg", messageFile, "message"); err != nil {
+		return "", err
+	}
+	if !noVerify {
+		if err := runHook(repo, "commit-msg", messageFile); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := os.ReadFile(messageFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message: %w", err)
+	}
+
+	message = cleanupMessage(string(data))
+	if message == "" {
+		return "", fmt.Errorf("aborting commit due to empty commit message")
+	}
+	return message, nil
+}
+
+// cleanupMessage strips trailing whitespace and leading, trailing and
+// repeated blank lines, like git's whitespace cleanup mode
+func cleanupMessage(message string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// buildCommitMessage appends the trailers requested in opts to message.
+// Signed-off-by is added last, matching git commit --signoff.
+func buildCommitMessage(message string, config *GitConfig, opts CommitOptions) (string, error) {
+	var trailers []Trailer
+	for _, raw := range opts.Trailers {
+		trailer, err := ParseTrailer(raw)
+		if err != nil {
+			return "", err
+		}
+		trailers = append(trailers, trailer)
+	}
+
+	for _, coAuthor := range opts.CoAuthors {
+		identity, err := ResolveCoAuthor(coAuthor)
+		if err != nil {
+			return "", err
+		}
+		trailers = append(trailers, CoAuthorTrailer(identity))
+	}
+
+	if opts.Signoff {
+		trailers = append(trailers, SignoffTrailer(config))
+	}
+
+	return AppendTrailers(message, trailers), nil
+}
+
 // Add these new functions
 func GetGitConfig() (*GitConfig, error) {
-	repo, err := git.PlainOpen(".")
+	repo, err := openRepository()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open git repository: %w", err)
 	}
@@ -333,7 +815,7 @@ func detectGoPackage(content string) string {
 
 // GetAllChanges returns both staged and unstaged changes
 func GetAllChanges() ([]FileChange, error) {
-	repo, err := git.PlainOpen(".")
+	repo, err := openRepository()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open git repository: %w", err)
 	}
@@ -348,59 +830,194 @@ func GetAllChanges() ([]FileChange, error) {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
+	tree, err := headTree(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+
+	renames, err := detectRenames(repo, tree, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect renames: %w", err)
+	}
+	renamedFrom := make(map[string]bool)
+	for _, pair := range renames {
+		if pair.Status == "renamed" {
+			renamedFrom[pair.OldPath] = true
+		}
+	}
+
 	var changes []FileChange
 	for path, fileStatus := range status {
-		var statusStr string
-		if fileStatus.Staging == git.Deleted || fileStatus.Worktree == git.Deleted {
-			statusStr = "deleted"
-		} else {
-			statusStr = statusToString(fileStatus.Worktree)
+		if renamedFrom[path] {
+			continue
 		}
 
 		change := FileChange{
-			Path:   path,
-			Status: statusStr,
-			Staged: fileStatus.Staging != git.Unmodified,
+			Path:     path,
+			Index:    byte(fileStatus.Staging),
+			Worktree: byte(fileStatus.Worktree),
+			Staged:   fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked,
+		}
+		if pair, ok := renames[path]; ok {
+			change.OldPath = pair.OldPath
+			change.Index = renameStatusCode(pair.Status)
+		}
+
+		// Describe the staged change, or the unstaged one for files not staged
+		switch {
+		case fileStatus.Staging == git.Untracked:
+			change.Status = "untracked"
+		case change.OldPath != "":
+			change.Status = renames[path].Status
+		case change.Staged:
+			change.Status = statusToString(fileStatus.Staging)
+		default:
+			change.Status = statusToString(fileStatus.Worktree)
 		}
 		changes = append(changes, change)
 	}
 
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
 	return changes, nil
 }
 
-// StageFile stages a single file
+// renameStatusCode returns the git status code of a detected rename or copy
+func renameStatusCode(status string) byte {
+	if status == "copied" {
+		return byte(git.Copied)
+	}
+	return byte(git.Renamed)
+}
+
+// StageFile stages a single file like git add -A: new and modified files
+// are added with their current mode, and files deleted from the worktree
+// are removed from the index
 func StageFile(path string) error {
-	repo, err := git.PlainOpen(".")
+	repo, err := openRepository()
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
 
-	w, err := repo.Worktree()
+	if useGitBinary() {
+		return runPathCommand(repo, "add", "-A", "--", path)
+	}
+
+	root, err := worktreeRoot(repo)
 	if err != nil {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
+	if _, err := os.Lstat(filepath.Join(root, path)); os.IsNotExist(err) {
+		idx, err := repo.Storer.Index()
+		if err != nil {
+			return fmt.Errorf("failed to read index: %w", err)
+		}
+		if err := removeIndexEntry(idx, path); err != nil {
+			return fmt.Errorf("failed to stage deletion of %s: %w", path, err)
+		}
+		if err := repo.Storer.SetIndex(idx); err != nil {
+			return fmt.Errorf("failed to write index: %w", err)
+		}
+		return nil
+	}
 
-	_, err = w.Add(path)
-	return err
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if _, err := w.Add(path); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	return nil
 }
 
 // RestoreStaged unstages a single file
 func RestoreStaged(path string) error {
-	repo, err := git.PlainOpen(".")
+	repo, err := openRepository()
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
 
-	_, err = repo.Worktree()
+	// git restore needs a HEAD to restore from
+	if _, err := repo.Head(); err == nil && useGitBinary() {
+		return runPathCommand(repo, "restore", "--staged", "--", path)
+	}
+
+	if err := resetIndexEntry(repo, path); err != nil {
+		return fmt.Errorf("failed to unstage file: %w", err)
+	}
+
+	return nil
+}
+
+// runPathCommand runs a git command whose pathspecs are literal paths and
+// reports git's own message on failure
+func runPathCommand(repo *git.Repository, args ...string) error {
+	cmd, err := gitCommand(repo, append([]string{"--literal-pathspecs"}, args...)...)
 	if err != nil {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("git %s failed: %s", args[0], message)
+		}
+		return fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return nil
+}
 
-	// Use git command directly for single file unstaging
-	cmd := exec.Command("git", "restore", "--staged", path)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to unstage file: %w", err)
+// resetIndexEntry sets the index entry of path back to its HEAD version, or
+// removes it if the file is not in HEAD, like git restore --staged
+func resetIndexEntry(repo *git.Repository, path string) error {
+	tree, err := headTree(repo)
+	if err != nil {
+		return fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var headEntry *object.TreeEntry
+	if tree != nil {
+		if entry, err := tree.FindEntry(path); err == nil {
+			headEntry = entry
+		}
 	}
 
+	if headEntry == nil {
+		if err := removeIndexEntry(idx, path); err != nil {
+			return err
+		}
+		return repo.Storer.SetIndex(idx)
+	}
+
+	setIndexEntry(idx, path, headEntry.Hash, headEntry.Mode)
+	return repo.Storer.SetIndex(idx)
+}
+
+// setIndexEntry points the index entry of path at a blob, adding the entry
+// if needed. Clearing the cached stat data makes status re-hash the worktree
+// file.
+func setIndexEntry(idx *index.Index, path string, hash plumbing.Hash, mode filemode.FileMode) {
+	entry, err := idx.Entry(path)
+	if err != nil {
+		entry = idx.Add(path)
+	}
+
+	*entry = index.Entry{
+		Name: path,
+		Hash: hash,
+		Mode: mode,
+	}
+}
+
+// removeIndexEntry removes path from the index if it is there
+func removeIndexEntry(idx *index.Index, path string) error {
+	if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+		return err
+	}
 	return nil
 }
@@ -1,15 +1,26 @@
 package git
 
 import (
+	"bytes"
+	"image"
+	"image/png"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/ozankasikci/gitai/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func TestMain(m *testing.M) {
+	logger.InitDefault()
+	os.Exit(m.Run())
+}
+
 func setupTestRepo(t *testing.T) string {
 	// Create a temporary directory for the test repo
 	tmpDir, err := os.MkdirTemp("", "git-test-*")
@@ -153,4 +164,452 @@ func TestGetGitConfig(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Test User", config.Name)
 	assert.Equal(t, "test@example.com", config.Email)
-} 
\ No newline at end of file
+}
+
+func TestGetStagedContentOmitsIgnoredFiles(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	createTestFile(t, tmpDir, IgnoreFileName, "# lockfiles\n*.lock\n")
+	createTestFile(t, tmpDir, "main.go", "package main\n")
+	createTestFile(t, tmpDir, "deps.lock", "lockfile-content\n")
+	cmd := exec.Command("git", "add", "main.go", "deps.lock")
+	err = cmd.Run()
+	require.NoError(t, err)
+
+	content, err := GetStagedContent(ContentOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "deps.lock (status: added)")
+	assert.Contains(t, content, "=== deps.lock ===\n(changed, content omitted)")
+	assert.NotContains(t, content, "lockfile-content")
+	assert.Contains(t, content, "package main")
+
+	ignored, err := IgnoredPaths([]string{"main.go", "deps.lock", "sub/other.lock"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"deps.lock": true, "sub/other.lock": true}, ignored)
+}
+
+func TestGetStagedContentDescribesBinaryAndLargeFiles(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	var img bytes.Buffer
+	require.NoError(t, png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 64, 32))))
+	createTestFile(t, tmpDir, "logo.png", img.String())
+	createTestFile(t, tmpDir, "model.bin", "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345678\n")
+	createTestFile(t, tmpDir, "large.txt", strings.Repeat("a line of text\n", 100))
+	cmd := exec.Command("git", "add", ".")
+	err = cmd.Run()
+	require.NoError(t, err)
+
+	content, err := GetStagedContent(ContentOptions{MaxFileSize: 1024})
+	require.NoError(t, err)
+	assert.Contains(t, content, "=== logo.png ===\nBinary file added (image/png, ")
+	assert.Contains(t, content, "64x32), content omitted")
+	assert.Contains(t, content, "=== model.bin ===\nGit LFS object added (11.8 MB), content omitted")
+	assert.Contains(t, content, "=== large.txt ===\nLarge file added (text/plain; charset=utf-8, 1.5 KB), content omitted")
+	assert.NotContains(t, content, "a line of text")
+}
+
+func TestGetStagedChangesDetectsRenamesAndCopies(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	original := strings.Repeat("some shared line of content\n", 20)
+	createTestFile(t, tmpDir, "old.txt", original)
+	createTestFile(t, tmpDir, "source.txt", original+"source only\n")
+	cmd := exec.Command("git", "add", ".")
+	require.NoError(t, cmd.Run())
+	cmd = exec.Command("git", "commit", "-m", "initial commit")
+	require.NoError(t, cmd.Run())
+
+	// Rename with a small edit, and copy a file that is also modified
+	cmd = exec.Command("git", "mv", "old.txt", "new.txt")
+	require.NoError(t, cmd.Run())
+	createTestFile(t, tmpDir, "new.txt", original+"one more line\n")
+	createTestFile(t, tmpDir, "copy.txt", original+"source only\n")
+	createTestFile(t, tmpDir, "source.txt", original+"source changed\n")
+	cmd = exec.Command("git", "add", ".")
+	require.NoError(t, cmd.Run())
+
+	changes, err := GetStagedChanges()
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, "copy.txt", changes[0].Path)
+	assert.Equal(t, "source.txt", changes[0].OldPath)
+	assert.Equal(t, "copied", changes[0].Status)
+	assert.Equal(t, 100, changes[0].Similarity)
+
+	assert.Equal(t, "new.txt", changes[1].Path)
+	assert.Equal(t, "old.txt", changes[1].OldPath)
+	assert.Equal(t, "renamed", changes[1].Status)
+	assert.Equal(t, "old.txt → new.txt", changes[1].DisplayPath())
+	assert.Greater(t, changes[1].Similarity, 90)
+
+	assert.Equal(t, "source.txt", changes[2].Path)
+	assert.Equal(t, "modified", changes[2].Status)
+
+	content, err := GetStagedContent(ContentOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "old.txt → new.txt (status: renamed, ")
+	assert.Contains(t, content, "=== source.txt → copy.txt ===\n(copied without changes)")
+	assert.Contains(t, content, "+one more line")
+}
+
+func TestGetDiffForFileMatchesGitBinary(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	createTestFile(t, tmpDir, "test.txt", "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n")
+	cmd := exec.Command("git", "add", "test.txt")
+	require.NoError(t, cmd.Run())
+	cmd = exec.Command("git", "commit", "-m", "initial commit")
+	require.NoError(t, cmd.Run())
+
+	createTestFile(t, tmpDir, "test.txt", "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine")
+	createTestFile(t, tmpDir, "new.txt", "new file\n")
+	cmd = exec.Command("git", "add", ".")
+	require.NoError(t, cmd.Run())
+
+	repo, err := gogit.PlainOpen(".")
+	require.NoError(t, err)
+
+	base, err := headBase(repo)
+	require.NoError(t, err)
+
+	for _, path := range []string{"test.txt", "new.txt"} {
+		diff, err := getDiffForFile(repo, base, "", path)
+		require.NoError(t, err)
+
+		cmd = exec.Command("git", "diff", "--cached", "--", path)
+		expected, err := cmd.Output()
+		require.NoError(t, err)
+
+		// go-git writes full object hashes in the index line, so compare the hunks
+		hunks := func(s string) string { return s[strings.Index(s, "@@"):] }
+		assert.Equal(t, hunks(string(expected)), hunks(diff))
+	}
+}
+
+func TestGetStagedChangesFromSubdirectoryAndLinkedWorktree(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "sub", "dir"), 0755))
+	createTestFile(t, tmpDir, "sub/dir/test.txt", "test content")
+	createTestFile(t, tmpDir, ".gitaiignore", "ignored.txt\n")
+	createTestFile(t, tmpDir, "ignored.txt", "ignored content")
+	cmd := exec.Command("git", "add", ".")
+	cmd.Dir = tmpDir
+	require.NoError(t, cmd.Run())
+
+	// Paths are relative to the worktree root, not the current directory
+	err = os.Chdir(filepath.Join(tmpDir, "sub", "dir"))
+	require.NoError(t, err)
+
+	changes, err := GetStagedChanges()
+	require.NoError(t, err)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, "sub/dir/test.txt", changes[2].Path)
+
+	content, err := GetStagedContent(ContentOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "+test content")
+	assert.NotContains(t, content, "ignored content")
+
+	require.NoError(t, CommitChanges("Add files", CommitOptions{}))
+
+	// A linked worktree has its own index
+	worktreeDir := filepath.Join(t.TempDir(), "linked")
+	cmd = exec.Command("git", "worktree", "add", "-b", "feature", worktreeDir)
+	cmd.Dir = tmpDir
+	require.NoError(t, cmd.Run())
+
+	err = os.Chdir(filepath.Join(worktreeDir, "sub"))
+	require.NoError(t, err)
+
+	createTestFile(t, worktreeDir, "sub/feature.txt", "feature content")
+	require.NoError(t, StageFile("sub/feature.txt"))
+
+	changes, err = GetStagedChanges()
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "sub/feature.txt", changes[0].Path)
+
+	require.NoError(t, CommitChanges("Add feature", CommitOptions{}))
+
+	cmd = exec.Command("git", "log", "-1", "--format=%s", "feature")
+	cmd.Dir = tmpDir
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Add feature\n", string(output))
+}
+
+func TestCommitChangesAmend(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	// Amending needs a commit
+	err = CommitChanges("feat: nothing", CommitOptions{Amend: true})
+	assert.Error(t, err)
+
+	createTestFile(t, tmpDir, "base.txt", "base")
+	cmd := exec.Command("git", "add", "base.txt")
+	require.NoError(t, cmd.Run())
+	cmd = exec.Command("git", "commit", "-m", "Initial commit")
+	require.NoError(t, cmd.Run())
+
+	createTestFile(t, tmpDir, "first.txt", "first")
+	cmd = exec.Command("git", "add", "first.txt")
+	require.NoError(t, cmd.Run())
+	cmd = exec.Command("git", "commit", "-m", "Add first file")
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Original Author",
+		"GIT_AUTHOR_EMAIL=original@example.com",
+		"GIT_AUTHOR_DATE=2020-01-02T03:04:05Z")
+	require.NoError(t, cmd.Run())
+
+	createTestFile(t, tmpDir, "second.txt", "second")
+	cmd = exec.Command("git", "add", "second.txt")
+	require.NoError(t, cmd.Run())
+
+	// HEAD's changes are combined with the staged ones
+	changes, err := GetAmendChanges()
+	require.NoError(t, err)
+	assert.Equal(t, []StagedChange{
+		{Path: "first.txt", Status: "added"},
+		{Path: "second.txt", Status: "added"},
+	}, changes)
+
+	content, err := GetStagedContent(ContentOptions{Amend: true})
+	require.NoError(t, err)
+	assert.Contains(t, content, "=== Current Commit Message ===\n\nAdd first file\n")
+	assert.Contains(t, content, "+first")
+	assert.Contains(t, content, "+second")
+	assert.NotContains(t, content, "base.txt")
+
+	// The author and author date are preserved
+	err = CommitChanges("feat: add first and second files", CommitOptions{Amend: true})
+	require.NoError(t, err)
+
+	cmd = exec.Command("git", "log", "-1", "--format=%an <%ae> %aI|%cn|%s")
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Original Author <original@example.com> 2020-01-02T03:04:05+00:00|Test User|feat: add first and second files\n", string(output))
+
+	cmd = exec.Command("git", "rev-list", "--count", "HEAD")
+	output, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "2\n", string(output))
+
+	cmd = exec.Command("git", "show", "--name-only", "--format=", "HEAD")
+	output, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "first.txt\nsecond.txt\n", string(output))
+
+	// --reset-author takes over authorship
+	err = CommitChanges("feat: add two files", CommitOptions{Amend: true, ResetAuthor: true})
+	require.NoError(t, err)
+
+	cmd = exec.Command("git", "log", "-1", "--format=%an <%ae>")
+	output, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Test User <test@example.com>\n", string(output))
+}
+
+func TestGetAllChangesSeparatesIndexAndWorktree(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	for _, name := range []string{"both.txt", "staged.txt", "unstaged.txt"} {
+		createTestFile(t, tmpDir, name, name+"\n")
+	}
+	cmd := exec.Command("git", "add", ".")
+	require.NoError(t, cmd.Run())
+	cmd = exec.Command("git", "commit", "-m", "initial commit")
+	require.NoError(t, cmd.Run())
+
+	// Stage some changes and keep editing afterwards
+	createTestFile(t, tmpDir, "both.txt", "both.txt\nstaged\n")
+	createTestFile(t, tmpDir, "staged.txt", "staged.txt\nstaged\n")
+	createTestFile(t, tmpDir, "added.txt", "added\n")
+	cmd = exec.Command("git", "add", "both.txt", "staged.txt", "added.txt")
+	require.NoError(t, cmd.Run())
+	createTestFile(t, tmpDir, "both.txt", "both.txt\nstaged\nunstaged\n")
+	createTestFile(t, tmpDir, "added.txt", "added\nedited\n")
+	createTestFile(t, tmpDir, "unstaged.txt", "unstaged.txt\nunstaged\n")
+	createTestFile(t, tmpDir, "untracked.txt", "untracked\n")
+
+	changes, err := GetAllChanges()
+	require.NoError(t, err)
+
+	got := make(map[string]string)
+	for _, change := range changes {
+		got[change.Path] = change.ShortStatus()
+	}
+	assert.Equal(t, map[string]string{
+		"added.txt":     "AM",
+		"both.txt":      "MM",
+		"staged.txt":    "M ",
+		"unstaged.txt":  " M",
+		"untracked.txt": "??",
+	}, got)
+
+	for _, change := range changes {
+		switch change.Path {
+		case "added.txt", "both.txt":
+			assert.True(t, change.Staged, change.Path)
+			assert.True(t, change.HasUnstagedChanges(), change.Path)
+		case "staged.txt":
+			assert.True(t, change.Staged)
+			assert.False(t, change.HasUnstagedChanges())
+		case "unstaged.txt":
+			assert.False(t, change.Staged)
+		case "untracked.txt":
+			assert.False(t, change.Staged)
+			assert.Equal(t, "untracked", change.Status)
+		}
+	}
+}
+
+func TestStageFileHandlesDeletionsRenamesAndModes(t *testing.T) {
+	for _, preferBinary := range []bool{false, true} {
+		t.Run(map[bool]string{false: "go-git", true: "git binary"}[preferBinary], func(t *testing.T) {
+			PreferGitBinary = preferBinary
+			defer func() { PreferGitBinary = false }()
+
+			tmpDir := setupTestRepo(t)
+			defer os.RemoveAll(tmpDir)
+
+			originalDir, err := os.Getwd()
+			require.NoError(t, err)
+			defer os.Chdir(originalDir)
+			require.NoError(t, os.Chdir(tmpDir))
+
+			createTestFile(t, tmpDir, "deleted.txt", "deleted\n")
+			createTestFile(t, tmpDir, "old.txt", strings.Repeat("renamed content\n", 10))
+			createTestFile(t, tmpDir, "script.sh", "echo hi\n")
+			cmd := exec.Command("git", "add", ".")
+			require.NoError(t, cmd.Run())
+			cmd = exec.Command("git", "commit", "-m", "initial commit")
+			require.NoError(t, cmd.Run())
+
+			require.NoError(t, os.Remove(filepath.Join(tmpDir, "deleted.txt")))
+			require.NoError(t, os.Rename(filepath.Join(tmpDir, "old.txt"), filepath.Join(tmpDir, "new.txt")))
+			require.NoError(t, os.Chmod(filepath.Join(tmpDir, "script.sh"), 0755))
+
+			for _, path := range []string{"deleted.txt", "old.txt", "new.txt", "script.sh"} {
+				require.NoError(t, StageFile(path), path)
+			}
+
+			cmd = exec.Command("git", "status", "--porcelain")
+			output, err := cmd.Output()
+			require.NoError(t, err)
+			assert.Equal(t, "D  deleted.txt\nR  old.txt -> new.txt\nM  script.sh\n", string(output))
+
+			cmd = exec.Command("git", "diff", "--cached", "--summary", "--", "script.sh")
+			output, err = cmd.Output()
+			require.NoError(t, err)
+			assert.Contains(t, string(output), "mode change 100644 => 100755 script.sh")
+
+			// Unstaging both sides of the rename restores the original index
+			for _, path := range []string{"deleted.txt", "old.txt", "new.txt", "script.sh"} {
+				require.NoError(t, RestoreStaged(path), path)
+			}
+			cmd = exec.Command("git", "status", "--porcelain")
+			output, err = cmd.Output()
+			require.NoError(t, err)
+			assert.Equal(t, " D deleted.txt\n D old.txt\n M script.sh\n?? new.txt\n", string(output))
+		})
+	}
+}
+
+func TestGetStagedDiffStats(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	createTestFile(t, tmpDir, "edited.txt", "one\ntwo\nthree\n")
+	createTestFile(t, tmpDir, "removed.txt", "a\nb\n")
+	cmd := exec.Command("git", "add", ".")
+	require.NoError(t, cmd.Run())
+	cmd = exec.Command("git", "commit", "-m", "initial commit")
+	require.NoError(t, cmd.Run())
+
+	createTestFile(t, tmpDir, "edited.txt", "one\n2\nthree\nfour\n")
+	createTestFile(t, tmpDir, "added.txt", "new\n")
+	createTestFile(t, tmpDir, "image.bin", "\x00\x01\x02")
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "removed.txt")))
+	cmd = exec.Command("git", "add", "-A")
+	require.NoError(t, cmd.Run())
+
+	stats, err := GetStagedDiffStats(false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []DiffStat{
+		{Path: "added.txt", Added: 1},
+		{Path: "edited.txt", Added: 2, Deleted: 1},
+		{Path: "image.bin", Binary: true},
+		{Path: "removed.txt", Deleted: 2},
+	}, stats)
+}
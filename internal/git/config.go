@@ -0,0 +1,85 @@
+package git
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// getConfigValue resolves a git config option the way git does, preferring
+// the repository config over the global and then the system config.
+// An empty subsection reads the option from the section itself.
+func getConfigValue(repo *git.Repository, section, subsection, key string) string {
+	var scopes []*config.Config
+
+	local, err := repo.Config()
+	if err != nil {
+		logger.Debugf("Failed to read repository config: %v", err)
+	} else {
+		scopes = append(scopes, local)
+	}
+
+	for _, scope := range []config.Scope{config.GlobalScope, config.SystemScope} {
+		cfg, err := config.LoadConfig(scope)
+		if err != nil {
+			logger.Debugf("Failed to read git config for scope %d: %v", scope, err)
+			continue
+		}
+		scopes = append(scopes, cfg)
+	}
+
+	for _, cfg := range scopes {
+		if cfg.Raw == nil || !cfg.Raw.HasSection(section) {
+			continue
+		}
+
+		s := cfg.Raw.Section(section)
+		var value string
+		if subsection == "" {
+			value = s.Option(key)
+		} else if s.HasSubsection(subsection) {
+			value = s.Subsection(subsection).Option(key)
+		}
+
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// getConfigBool resolves a boolean git config option
+func getConfigBool(repo *git.Repository, section, subsection, key string) bool {
+	switch strings.ToLower(getConfigValue(repo, section, subsection, key)) {
+	case "true", "yes", "on", "1":
+		return true
+	default:
+		return false
+	}
+}
+
+// Editor returns the editor command git would use: GIT_EDITOR, core.editor,
+// VISUAL, EDITOR and finally vi. The value may contain arguments and is meant
+// to be run by a shell.
+func Editor() string {
+	if editor := os.Getenv("GIT_EDITOR"); editor != "" {
+		return editor
+	}
+
+	if repo, err := openRepository(); err == nil {
+		if editor := getConfigValue(repo, "core", "", "editor"); editor != "" {
+			return editor
+		}
+	}
+
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(env); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
@@ -0,0 +1,260 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/ozankasikci/gitai/internal/logger"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// PreferGitBinary makes diff generation, staging and unstaging shell out to
+// the git binary when it is available instead of using go-git plumbing
+var PreferGitBinary bool
+
+var (
+	gitBinaryOnce  sync.Once
+	gitBinaryFound bool
+)
+
+// useGitBinary reports whether the git binary should be used
+func useGitBinary() bool {
+	return PreferGitBinary && hasGitBinary()
+}
+
+// hasGitBinary reports whether the git binary is installed
+func hasGitBinary() bool {
+	gitBinaryOnce.Do(func() {
+		_, err := exec.LookPath("git")
+		gitBinaryFound = err == nil
+		if !gitBinaryFound {
+			logger.Debugf("git binary not found, falling back to go-git")
+		}
+	})
+	return gitBinaryFound
+}
+
+// diffFile is one side of a file diff
+type diffFile struct {
+	path string
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+func (f *diffFile) Hash() plumbing.Hash     { return f.hash }
+func (f *diffFile) Mode() filemode.FileMode { return f.mode }
+func (f *diffFile) Path() string            { return f.path }
+
+type diffChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c *diffChunk) Content() string       { return c.content }
+func (c *diffChunk) Type() fdiff.Operation { return c.op }
+
+type filePatch struct {
+	from, to *diffFile
+	chunks   []fdiff.Chunk
+}
+
+func (p *filePatch) IsBinary() bool { return false }
+
+func (p *filePatch) Files() (fdiff.File, fdiff.File) {
+	// Nil pointers must be returned as untyped nil for the encoder
+	var from, to fdiff.File
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+func (p *filePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+type patch struct {
+	filePatches []fdiff.FilePatch
+}
+
+func (p *patch) FilePatches() []fdiff.FilePatch { return p.filePatches }
+func (p *patch) Message() string                { return "" }
+
+// encodeDiff renders a unified diff between two versions of a file, honoring
+// diff.context and diff.noprefix. A nil side means the file does not exist.
+func encodeDiff(repo *git.Repository, from, to *diffFile, fromContent, toContent string) (string, error) {
+	var chunks []fdiff.Chunk
+	for _, d := range diff.Do(fromContent, toContent) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		chunks = append(chunks, &diffChunk{content: d.Text, op: op})
+	}
+
+	contextLines := fdiff.DefaultContextLines
+	if value := getConfigValue(repo, "diff", "", "context"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			contextLines = n
+		}
+	}
+
+	var builder strings.Builder
+	encoder := fdiff.NewUnifiedEncoder(&builder, contextLines)
+	if getConfigBool(repo, "diff", "", "noprefix") {
+		encoder.SetSrcPrefix("").SetDstPrefix("")
+	}
+
+	err := encoder.Encode(&patch{filePatches: []fdiff.FilePatch{
+		&filePatch{from: from, to: to, chunks: chunks},
+	}})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode diff: %w", err)
+	}
+
+	return builder.String(), nil
+}
+
+// headTree returns the tree of the HEAD commit, or nil before the first commit
+func headTree(repo *git.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// emptyTreeHash is git's well-known empty tree, used as the base when
+// amending a root commit
+const emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// diffBase is the commit that staged changes are compared against
+type diffBase struct {
+	// rev names the commit for the git binary; empty before the first commit
+	rev string
+	// tree is nil before the first commit and when amending a root commit
+	tree *object.Tree
+}
+
+// headBase compares staged changes against HEAD
+func headBase(repo *git.Repository) (*diffBase, error) {
+	tree, err := headTree(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+	if tree == nil {
+		return &diffBase{}, nil
+	}
+	return &diffBase{rev: "HEAD", tree: tree}, nil
+}
+
+// amendBase compares staged changes against HEAD's first parent, so the
+// changes of the commit being amended are included
+func amendBase(repo *git.Repository) (*diffBase, error) {
+	head, err := repo.Head()
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return nil, fmt.Errorf("nothing to amend: no commits yet")
+		}
+		return nil, err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	if commit.NumParents() == 0 {
+		return &diffBase{rev: emptyTreeHash}, nil
+	}
+
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parent of HEAD: %w", err)
+	}
+	tree, err := parent.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parent tree: %w", err)
+	}
+	return &diffBase{rev: "HEAD^", tree: tree}, nil
+}
+
+// treeFile reads a file from tree, returning nil if it does not exist
+func treeFile(repo *git.Repository, tree *object.Tree, path string) (*diffFile, string, error) {
+	if tree == nil {
+		return nil, "", nil
+	}
+
+	entry, err := tree.FindEntry(path)
+	if err != nil {
+		return nil, "", nil
+	}
+
+	content, err := readBlob(repo, entry.Hash, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	return &diffFile{path: path, hash: entry.Hash, mode: entry.Mode}, string(content), nil
+}
+
+// indexFile reads a staged file, returning nil if it is not in the index
+func indexFile(repo *git.Repository, path string) (*diffFile, string, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, "", err
+	}
+
+	entry, err := idx.Entry(path)
+	if err != nil {
+		return nil, "", nil
+	}
+
+	content, err := readBlob(repo, entry.Hash, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	return &diffFile{path: path, hash: entry.Hash, mode: entry.Mode}, string(content), nil
+}
+
+// stagedDiff generates the diff of path between tree and the index using
+// go-git plumbing only. For renames and copies the tree side is read from
+// oldPath.
+func stagedDiff(repo *git.Repository, tree *object.Tree, oldPath, path string) (string, error) {
+	if oldPath == "" {
+		oldPath = path
+	}
+	from, fromContent, err := treeFile(repo, tree, oldPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read committed %s: %w", oldPath, err)
+	}
+
+	to, toContent, err := indexFile(repo, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from the index: %w", path, err)
+	}
+
+	if from == nil && to == nil {
+		return "", nil
+	}
+	return encodeDiff(repo, from, to, fromContent, toContent)
+}
@@ -0,0 +1,302 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// DiscardBackupRef points at the latest backup of discarded changes. Each
+// backup commit holds the discarded worktree files, lists their paths in its
+// message and has the previous backup as parent.
+const DiscardBackupRef = "refs/gitai/discard-backup"
+
+// discardBackupHeader starts the message of backup commits; the discarded
+// paths follow, one per line
+const discardBackupHeader = "gitai discard backup"
+
+// DiscardChanges makes the worktree versions of paths match the index, like
+// git restore, and deletes untracked files. The discarded versions are saved
+// under DiscardBackupRef first so UndoDiscard can bring them back.
+func DiscardChanges(paths []string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if err := backupWorktreeFiles(repo, root, paths); err != nil {
+		return fmt.Errorf("failed to back up changes: %w", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	for _, path := range paths {
+		entry, err := idx.Entry(path)
+		if err != nil {
+			// Not in the index, so the file is untracked
+			if err := removeWorktreeFile(root, path); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", path, err)
+			}
+			continue
+		}
+		content, err := readBlob(repo, entry.Hash, 0)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from the index: %w", path, err)
+		}
+		if err := writeWorktreeFile(root, path, content, entry.Mode); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+	}
+
+	// The restored files no longer match the cached stat data
+	for _, path := range paths {
+		if entry, err := idx.Entry(path); err == nil {
+			setIndexEntry(idx, path, entry.Hash, entry.Mode)
+		}
+	}
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// UndoDiscard puts back the files of the latest discard and drops its
+// backup. It returns the restored paths.
+func UndoDiscard() ([]string, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	ref, err := repo.Reference(plumbing.ReferenceName(DiscardBackupRef), true)
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", DiscardBackupRef, err)
+	}
+	backup, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup commit: %w", err)
+	}
+	tree, err := backup.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup tree: %w", err)
+	}
+
+	paths := backupPaths(backup.Message)
+	for _, path := range paths {
+		entry, err := tree.FindEntry(path)
+		if err != nil {
+			// The file was deleted in the worktree when it was discarded
+			if err := removeWorktreeFile(root, path); err != nil {
+				return nil, fmt.Errorf("failed to delete %s: %w", path, err)
+			}
+			continue
+		}
+		content, err := readBlob(repo, entry.Hash, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup of %s: %w", path, err)
+		}
+		if err := writeWorktreeFile(root, path, content, entry.Mode); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+	}
+
+	// Step back to the previous backup
+	if len(backup.ParentHashes) > 0 {
+		err = repo.Storer.SetReference(plumbing.NewHashReference(ref.Name(), backup.ParentHashes[0]))
+	} else {
+		err = repo.Storer.RemoveReference(ref.Name())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", DiscardBackupRef, err)
+	}
+	return paths, nil
+}
+
+// backupWorktreeFiles saves the worktree versions of paths as a new backup
+// commit on DiscardBackupRef
+func backupWorktreeFiles(repo *git.Repository, root string, paths []string) error {
+	files := make(map[string]object.TreeEntry)
+	for _, path := range paths {
+		full := filepath.Join(root, path)
+		info, err := os.Lstat(full)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var content []byte
+		mode := filemode.Regular
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(full)
+			if err != nil {
+				return err
+			}
+			content, mode = []byte(target), filemode.Symlink
+		case info.Mode().IsRegular():
+			if content, err = os.ReadFile(full); err != nil {
+				return err
+			}
+			if info.Mode()&0111 != 0 {
+				mode = filemode.Executable
+			}
+		default:
+			return fmt.Errorf("%s is not a file", path)
+		}
+
+		hash, err := writeBlob(repo, string(content))
+		if err != nil {
+			return fmt.Errorf("failed to store %s: %w", path, err)
+		}
+		files[path] = object.TreeEntry{Hash: hash, Mode: mode}
+	}
+
+	treeHash, err := writeTree(repo, files)
+	if err != nil {
+		return err
+	}
+
+	signature := object.Signature{Name: "gitai", Email: "gitai@localhost", When: time.Now()}
+	if config, err := GetGitConfig(); err == nil && config.Name != "" && config.Email != "" {
+		signature.Name, signature.Email = config.Name, config.Email
+	}
+	commit := &object.Commit{
+		Author:    signature,
+		Committer: signature,
+		Message:   discardBackupHeader + "\n\n" + strings.Join(paths, "\n") + "\n",
+		TreeHash:  treeHash,
+	}
+
+	refName := plumbing.ReferenceName(DiscardBackupRef)
+	previous, err := repo.Reference(refName, true)
+	if err == nil {
+		commit.ParentHashes = []plumbing.Hash{previous.Hash()}
+	} else if err != plumbing.ErrReferenceNotFound {
+		return err
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return err
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return err
+	}
+	logger.Debugf("Saved discarded changes as %s", hash)
+	return repo.Storer.SetReference(plumbing.NewHashReference(refName, hash))
+}
+
+// backupPaths reads the discarded paths from a backup commit message
+func backupPaths(message string) []string {
+	_, list, _ := strings.Cut(message, "\n\n")
+	var paths []string
+	for _, line := range strings.Split(list, "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
+
+// writeTree stores a tree holding files by path, creating the trees of
+// their directories
+func writeTree(repo *git.Repository, files map[string]object.TreeEntry) (plumbing.Hash, error) {
+	tree := &object.Tree{}
+	subdirs := make(map[string]map[string]object.TreeEntry)
+	for path, entry := range files {
+		dir, rest, nested := strings.Cut(path, "/")
+		if !nested {
+			entry.Name = path
+			tree.Entries = append(tree.Entries, entry)
+			continue
+		}
+		if subdirs[dir] == nil {
+			subdirs[dir] = make(map[string]object.TreeEntry)
+		}
+		subdirs[dir][rest] = entry
+	}
+	for dir, subfiles := range subdirs {
+		hash, err := writeTree(repo, subfiles)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: dir, Mode: filemode.Dir, Hash: hash})
+	}
+
+	// Git orders tree entries as if directory names ended with a slash
+	sortName := func(entry object.TreeEntry) string {
+		if entry.Mode == filemode.Dir {
+			return entry.Name + "/"
+		}
+		return entry.Name
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortName(tree.Entries[i]) < sortName(tree.Entries[j])
+	})
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+// writeWorktreeFile replaces the worktree file at path with content
+func writeWorktreeFile(root, path string, content []byte, mode filemode.FileMode) error {
+	full := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if mode == filemode.Symlink {
+		return os.Symlink(string(content), full)
+	}
+	perm := os.FileMode(0644)
+	if mode == filemode.Executable {
+		perm = 0755
+	}
+	return os.WriteFile(full, content, perm)
+}
+
+// removeWorktreeFile deletes the worktree file at path if it exists, along
+// with the directories it leaves empty
+func removeWorktreeFile(root, path string) error {
+	if err := os.Remove(filepath.Join(root, path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for dir := filepath.Dir(path); dir != "."; dir = filepath.Dir(dir) {
+		// Fails once a directory is not empty
+		if os.Remove(filepath.Join(root, dir)) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscardChangesAndUndo(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	createTestFile(t, tmpDir, "modified.txt", "original\n")
+	createTestFile(t, tmpDir, "deleted.txt", "deleted\n")
+	createTestFile(t, tmpDir, "kept.txt", "kept\n")
+	cmd := exec.Command("git", "add", ".")
+	require.NoError(t, cmd.Run())
+	cmd = exec.Command("git", "commit", "-m", "initial commit")
+	require.NoError(t, cmd.Run())
+
+	createTestFile(t, tmpDir, "modified.txt", "original\nedited\n")
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "deleted.txt")))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "dir", "sub"), 0755))
+	createTestFile(t, tmpDir, "dir/sub/untracked.txt", "untracked\n")
+	createTestFile(t, tmpDir, "kept.txt", "kept\nedited\n")
+
+	status := func() string {
+		cmd := exec.Command("git", "status", "--porcelain", "--untracked-files=all")
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		return string(output)
+	}
+	before := status()
+
+	paths := []string{"modified.txt", "deleted.txt", "dir/sub/untracked.txt"}
+	require.NoError(t, DiscardChanges(paths))
+	assert.Equal(t, " M kept.txt\n", status())
+	assert.NoDirExists(t, filepath.Join(tmpDir, "dir"))
+
+	// The backup is a valid commit holding the discarded files
+	cmd = exec.Command("git", "show", DiscardBackupRef+":dir/sub/untracked.txt")
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "untracked\n", string(output))
+	cmd = exec.Command("git", "fsck", "--no-dangling")
+	require.NoError(t, cmd.Run())
+
+	restored, err := UndoDiscard()
+	require.NoError(t, err)
+	assert.Equal(t, paths, restored)
+	assert.Equal(t, before, status())
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "modified.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "original\nedited\n", string(content))
+
+	_, err = UndoDiscard()
+	assert.EqualError(t, err, "nothing to undo")
+}
+
+func TestStashFiles(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	createTestFile(t, tmpDir, "a.txt", "a\n")
+	createTestFile(t, tmpDir, "b.txt", "b\n")
+	cmd := exec.Command("git", "add", ".")
+	require.NoError(t, cmd.Run())
+	cmd = exec.Command("git", "commit", "-m", "initial commit")
+	require.NoError(t, cmd.Run())
+
+	createTestFile(t, tmpDir, "a.txt", "a\nchanged\n")
+	createTestFile(t, tmpDir, "b.txt", "b\nchanged\n")
+	createTestFile(t, tmpDir, "new.txt", "new\n")
+
+	require.NoError(t, StashFiles([]string{"a.txt", "new.txt"}, "wip: tweak a"))
+
+	cmd = exec.Command("git", "status", "--porcelain")
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, " M b.txt\n", string(output))
+
+	cmd = exec.Command("git", "stash", "list", "--format=%s")
+	output, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "wip: tweak a")
+}
@@ -0,0 +1,229 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// hookMarker identifies hooks written by gitai so they can be updated and
+// removed without touching hooks installed by other tools
+const hookMarker = "# Installed by gitai"
+
+// chainedHookSuffix is appended to an existing hook that gitai's hook replaces.
+// The gitai hook runs it first, and uninstalling restores it.
+const chainedHookSuffix = ".gitai-chained"
+
+const prepareCommitMsgHook = "prepare-commit-msg"
+
+const prepareCommitMsgScript = `#!/bin/sh
+%s (gitai hook install). Remove with: gitai hook uninstall
+HOOK_DIR=$(dirname "$0")
+if [ -x "$HOOK_DIR/prepare-commit-msg%s" ]; then
+	"$HOOK_DIR/prepare-commit-msg%s" "$@" || exit $?
+fi
+
+# Only fill in the message for plain commits, not for -m/-F, merges,
+# squashes or amends
+case "$2" in
+	""|template) ;;
+	*) exit 0 ;;
+esac
+
+%s hook run "$1" "$2" "$3" </dev/null || true
+`
+
+// hooksDir returns the directory git runs hooks from: core.hooksPath when
+// set, otherwise the hooks directory of the common git dir
+func hooksDir(repo *git.Repository) (string, error) {
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if hooksPath := getConfigValue(repo, "core", "", "hooksPath"); hooksPath != "" {
+		hooksPath = expandHome(hooksPath)
+		if !filepath.IsAbs(hooksPath) {
+			hooksPath = filepath.Join(root, hooksPath)
+		}
+		return hooksPath, nil
+	}
+
+	commonDir, err := commonGitDir(root)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, "hooks"), nil
+}
+
+// worktreeGitDir resolves the git dir of the worktree at root. Linked
+// worktrees have a .git file pointing at their private git dir.
+func worktreeGitDir(root string) (string, error) {
+	dotGit := filepath.Join(root, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", fmt.Errorf("failed to find git dir: %w", err)
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dotGit, err)
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("invalid .git file: %s", dotGit)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// commonGitDir resolves the git dir shared by all worktrees. The private git
+// dir of a linked worktree names the common dir in its commondir file.
+func commonGitDir(root string) (string, error) {
+	gitDir, err := worktreeGitDir(root)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		// Not a linked worktree
+		return gitDir, nil
+	}
+	commonDir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return filepath.Clean(commonDir), nil
+}
+
+// shellQuote quotes s for use as a single word in a POSIX shell script
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isGitaiHook(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(data), hookMarker)
+}
+
+// InstallPrepareCommitMsgHook writes a prepare-commit-msg hook that runs
+// executable to pre-fill the commit message. An existing hook that was not
+// installed by gitai is kept and run first.
+func InstallPrepareCommitMsgHook(executable string) (string, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	dir, err := hooksDir(repo)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	path := filepath.Join(dir, prepareCommitMsgHook)
+	if _, err := os.Stat(path); err == nil && !isGitaiHook(path) {
+		chained := path + chainedHookSuffix
+		if _, err := os.Stat(chained); err == nil {
+			return "", fmt.Errorf("cannot install hook: both %s and %s exist", path, chained)
+		}
+		if err := os.Rename(path, chained); err != nil {
+			return "", fmt.Errorf("failed to preserve existing hook: %w", err)
+		}
+		logger.Infof("Existing %s hook moved to %s and will still run", prepareCommitMsgHook, chained)
+	}
+
+	script := fmt.Sprintf(prepareCommitMsgScript, hookMarker, chainedHookSuffix, chainedHookSuffix, shellQuote(executable))
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+
+	return path, nil
+}
+
+// UninstallPrepareCommitMsgHook removes gitai's prepare-commit-msg hook and
+// restores the hook it replaced, if any
+func UninstallPrepareCommitMsgHook() (string, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	dir, err := hooksDir(repo)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, prepareCommitMsgHook)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("no %s hook installed", prepareCommitMsgHook)
+	}
+	if !isGitaiHook(path) {
+		return "", fmt.Errorf("%s was not installed by gitai", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove hook: %w", err)
+	}
+
+	chained := path + chainedHookSuffix
+	if _, err := os.Stat(chained); err == nil {
+		if err := os.Rename(chained, path); err != nil {
+			return "", fmt.Errorf("failed to restore previous hook: %w", err)
+		}
+		logger.Infof("Restored previous %s hook", prepareCommitMsgHook)
+	}
+
+	return path, nil
+}
+
+// runHook runs the named hook with args from the worktree root, like git does.
+// Missing or non-executable hooks are skipped. The hook's output is passed
+// through so linter messages reach the user.
+func runHook(repo *git.Repository, name string, args ...string) error {
+	dir, err := hooksDir(repo)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+	if info.Mode()&0111 == 0 {
+		logger.Debugf("Skipping %s hook: not executable", name)
+		return nil
+	}
+
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	logger.Debugf("Running %s hook: %s", name, path)
+	cmd := exec.Command(path, args...)
+	cmd.Dir = root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// No editor is involved when gitai commits
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=:")
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
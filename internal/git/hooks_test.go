@@ -0,0 +1,143 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallPrepareCommitMsgHook(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	// Hooks live in core.hooksPath, relative to the worktree root
+	gitConfig(t, tmpDir, "core.hooksPath", ".githooks")
+	hooksPath := filepath.Join(tmpDir, ".githooks")
+	require.NoError(t, os.MkdirAll(hooksPath, 0755))
+
+	// An existing hook keeps running after gitai's hook is installed
+	existing := "#!/bin/sh\necho 'Ticket: PROJ-1' >> \"$1\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(hooksPath, "prepare-commit-msg"), []byte(existing), 0755))
+
+	// A stand-in for gitai that writes a fixed message
+	fake := filepath.Join(t.TempDir(), "fake gitai")
+	require.NoError(t, os.WriteFile(fake, []byte("#!/bin/sh\nprintf 'Generated message\\n\\n' | cat - \"$3\" > \"$3.tmp\" && mv \"$3.tmp\" \"$3\"\n"), 0755))
+
+	path, err := InstallPrepareCommitMsgHook(fake)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(hooksPath, "prepare-commit-msg"), path)
+	assert.FileExists(t, path+chainedHookSuffix)
+
+	// Installing again updates the hook in place
+	_, err = InstallPrepareCommitMsgHook(fake)
+	require.NoError(t, err)
+
+	createTestFile(t, tmpDir, "test.txt", "test content")
+	cmd := exec.Command("git", "add", "test.txt")
+	require.NoError(t, cmd.Run())
+
+	cmd = exec.Command("git", "commit")
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	require.NoError(t, cmd.Run())
+
+	cmd = exec.Command("git", "log", "-1", "--format=%B")
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Generated message\n\nTicket: PROJ-1\n\n", string(output))
+
+	// Commits with -m are left alone by gitai
+	createTestFile(t, tmpDir, "test.txt", "changed content")
+	cmd = exec.Command("git", "commit", "-am", "Manual message")
+	require.NoError(t, cmd.Run())
+
+	cmd = exec.Command("git", "log", "-1", "--format=%B")
+	output, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Manual message\nTicket: PROJ-1\n\n", string(output))
+
+	// Uninstalling restores the original hook
+	_, err = UninstallPrepareCommitMsgHook()
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, existing, string(data))
+	assert.NoFileExists(t, path+chainedHookSuffix)
+
+	_, err = UninstallPrepareCommitMsgHook()
+	assert.Error(t, err)
+}
+
+func TestCommitChangesRunsHooks(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	gitConfig(t, tmpDir, "core.hooksPath", ".githooks")
+	hooksPath := filepath.Join(tmpDir, ".githooks")
+	require.NoError(t, os.MkdirAll(hooksPath, 0755))
+
+	writeHook := func(name, script string) {
+		require.NoError(t, os.WriteFile(filepath.Join(hooksPath, name), []byte("#!/bin/sh\n"+script), 0755))
+	}
+	writeHook("commit-msg", "echo 'Reviewed-by: Hook <hook@example.com>' >> \"$1\"\n")
+	writeHook("post-commit", "touch post-commit-ran\n")
+
+	createTestFile(t, tmpDir, "test.txt", "test content")
+	require.NoError(t, exec.Command("git", "add", "test.txt").Run())
+
+	// A failing pre-commit hook aborts the commit
+	writeHook("pre-commit", "exit 1\n")
+	err = CommitChanges("feat: add test file", CommitOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-commit hook failed")
+	assert.Error(t, exec.Command("git", "rev-parse", "HEAD").Run())
+
+	// --no-verify skips pre-commit and commit-msg, but not post-commit
+	err = CommitChanges("feat: add test file", CommitOptions{NoVerify: true})
+	require.NoError(t, err)
+
+	output, err := exec.Command("git", "log", "-1", "--format=%B").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "feat: add test file\n\n", string(output))
+	assert.FileExists(t, filepath.Join(tmpDir, "post-commit-ran"))
+
+	// commit-msg edits end up in the commit
+	writeHook("pre-commit", "exit 0\n")
+	createTestFile(t, tmpDir, "test.txt", "changed content")
+	require.NoError(t, exec.Command("git", "add", "test.txt").Run())
+
+	err = CommitChanges("fix: change test file", CommitOptions{})
+	require.NoError(t, err)
+
+	output, err = exec.Command("git", "log", "-1", "--format=%B").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "fix: change test file\nReviewed-by: Hook <hook@example.com>\n\n", string(output))
+
+	// A failing commit-msg hook aborts the commit
+	writeHook("commit-msg", "exit 1\n")
+	createTestFile(t, tmpDir, "test.txt", "more content")
+	require.NoError(t, exec.Command("git", "add", "test.txt").Run())
+
+	err = CommitChanges("fix: more changes", CommitOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "commit-msg hook failed")
+}
@@ -0,0 +1,467 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// hunkContext is the number of unchanged lines shown around each hunk.
+// Change blocks closer than twice this are merged into one hunk, as in
+// unified diffs.
+const hunkContext = 3
+
+// lineOp is one line of a line-level diff
+type lineOp struct {
+	op   fdiff.Operation
+	text string
+}
+
+// HunkLine is a line of a hunk
+type HunkLine struct {
+	// ID identifies the line within the file's diff for StageLines and
+	// UnstageLines
+	ID int
+	// Op is ' ' for context, '+' for added and '-' for removed lines
+	Op   byte
+	Text string
+	// NoNewline is set on the last line of a file without a final newline
+	NoNewline bool
+}
+
+// IsChange reports whether the line is added or removed
+func (l HunkLine) IsChange() bool {
+	return l.Op != ' '
+}
+
+// Hunk is a group of nearby changes between two versions of a file that can
+// be applied independently of the file's other hunks
+type Hunk struct {
+	// Header is the unified diff hunk header, e.g. "@@ -1,3 +1,4 @@"
+	Header string
+	Lines  []HunkLine
+
+	// start and end delimit the hunk's changed lines in the line diff
+	start, end int
+}
+
+// String renders the hunk in unified diff format
+func (h Hunk) String() string {
+	var builder strings.Builder
+	builder.WriteString(h.Header + "\n")
+	for _, line := range h.Lines {
+		builder.WriteString(string(line.Op) + line.Text + "\n")
+		if line.NoNewline {
+			builder.WriteString("\\ No newline at end of file\n")
+		}
+	}
+	return builder.String()
+}
+
+// ChangeIDs returns the IDs of the hunk's added and removed lines
+func (h Hunk) ChangeIDs() []int {
+	var ids []int
+	for _, line := range h.Lines {
+		if line.IsChange() {
+			ids = append(ids, line.ID)
+		}
+	}
+	return ids
+}
+
+// fileHunks is the line diff of a file split into hunks
+type fileHunks struct {
+	ops   []lineOp
+	hunks []Hunk
+}
+
+// computeHunks diffs two versions of a file line by line and groups the
+// changes into hunks
+func computeHunks(from, to string) *fileHunks {
+	var ops []lineOp
+	for _, d := range diff.Do(from, to) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line != "" {
+				ops = append(ops, lineOp{op: op, text: line})
+			}
+		}
+	}
+
+	fh := &fileHunks{ops: ops}
+
+	// Find the blocks of changed lines, merging blocks whose context would
+	// overlap
+	var blocks [][2]int
+	for i := 0; i < len(ops); {
+		if ops[i].op == fdiff.Equal {
+			i++
+			continue
+		}
+		start := i
+		for i < len(ops) && ops[i].op != fdiff.Equal {
+			i++
+		}
+		if n := len(blocks); n > 0 && start-blocks[n-1][1] <= 2*hunkContext {
+			blocks[n-1][1] = i
+		} else {
+			blocks = append(blocks, [2]int{start, i})
+		}
+	}
+
+	for _, block := range blocks {
+		fh.hunks = append(fh.hunks, fh.render(block[0], block[1]))
+	}
+	return fh
+}
+
+// render builds the hunk for the changed lines ops[start:end] with context
+func (fh *fileHunks) render(start, end int) Hunk {
+	from := max(start-hunkContext, 0)
+	to := min(end+hunkContext, len(fh.ops))
+
+	// Line numbers of the first line shown on each side
+	oldLine, newLine := 1, 1
+	for _, op := range fh.ops[:from] {
+		if op.op != fdiff.Add {
+			oldLine++
+		}
+		if op.op != fdiff.Delete {
+			newLine++
+		}
+	}
+
+	var lines []HunkLine
+	oldCount, newCount := 0, 0
+	for i, op := range fh.ops[from:to] {
+		prefix := byte(' ')
+		switch op.op {
+		case fdiff.Add:
+			prefix = '+'
+			newCount++
+		case fdiff.Delete:
+			prefix = '-'
+			oldCount++
+		default:
+			oldCount++
+			newCount++
+		}
+		lines = append(lines, HunkLine{
+			ID:        from + i,
+			Op:        prefix,
+			Text:      strings.TrimSuffix(op.text, "\n"),
+			NoNewline: !strings.HasSuffix(op.text, "\n"),
+		})
+	}
+
+	// Empty sides are reported at the line before, as git does
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+
+	return Hunk{
+		Header: fmt.Sprintf("@@ -%s +%s @@", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount)),
+		Lines:  lines,
+		start:  start,
+		end:    end,
+	}
+}
+
+func hunkRange(line, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// apply returns the old version of the file with only the selected hunks
+// applied. Selecting every hunk yields the new version.
+func (fh *fileHunks) apply(selected map[int]bool) string {
+	lines := make(map[int]bool)
+	for i, hunk := range fh.hunks {
+		if selected[i] {
+			for id := hunk.start; id < hunk.end; id++ {
+				lines[id] = true
+			}
+		}
+	}
+	return fh.applyLines(lines)
+}
+
+// applyLines returns the old version of the file with only the selected
+// added and removed lines, by HunkLine.ID, applied
+func (fh *fileHunks) applyLines(selected map[int]bool) string {
+	var builder strings.Builder
+	for i, op := range fh.ops {
+		switch op.op {
+		case fdiff.Equal:
+			builder.WriteString(op.text)
+		case fdiff.Delete:
+			if !selected[i] {
+				builder.WriteString(op.text)
+			}
+		case fdiff.Add:
+			if selected[i] {
+				builder.WriteString(op.text)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// patch renders a unified diff of path containing only the selected lines,
+// for git apply --recount. Unselected removals become context and unselected
+// additions are dropped. With reverse the patch is built to be applied in
+// reverse to the new version instead, so unselected additions become context
+// and unselected removals are dropped.
+func (fh *fileHunks) patch(path string, selected map[int]bool, reverse bool) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path))
+
+	for _, hunk := range fh.hunks {
+		var lines []HunkLine
+		changed := false
+		for _, line := range hunk.Lines {
+			switch {
+			case !line.IsChange() || selected[line.ID]:
+				changed = changed || line.IsChange()
+			case line.Op == '-' && !reverse, line.Op == '+' && reverse:
+				line.Op = ' '
+			default:
+				continue
+			}
+			lines = append(lines, line)
+		}
+		if !changed {
+			continue
+		}
+		// git apply --recount fixes up the line counts
+		builder.WriteString(Hunk{Header: hunk.Header, Lines: lines}.String())
+	}
+	return builder.String()
+}
+
+// ErrNotText is returned for files that cannot be staged by hunk, such as
+// binary files, symlinks and deleted files
+var ErrNotText = errors.New("file cannot be staged by hunk")
+
+// hunkSides holds the two versions of a file compared for hunk staging
+type hunkSides struct {
+	repo *git.Repository
+	path string
+	// inIndex and inHead tell whether the file exists on those sides
+	inIndex, inHead bool
+	mode            filemode.FileMode
+	hunks           *fileHunks
+}
+
+// unstagedSides diffs the index version of path against the worktree
+func unstagedSides(path string) (*hunkSides, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	info, err := os.Lstat(filepath.Join(root, path))
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, ErrNotText
+	}
+	worktree, err := os.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	sides := &hunkSides{repo: repo, path: path, mode: filemode.Regular}
+	if info.Mode()&0111 != 0 {
+		sides.mode = filemode.Executable
+	}
+
+	from, fromContent, err := indexFile(repo, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from the index: %w", path, err)
+	}
+	if from != nil {
+		sides.inIndex = true
+		sides.mode = from.mode
+		if from.mode != filemode.Regular && from.mode != filemode.Executable {
+			return nil, ErrNotText
+		}
+	}
+
+	if isBinaryContent([]byte(fromContent)) || isBinaryContent(worktree) {
+		return nil, ErrNotText
+	}
+	sides.hunks = computeHunks(fromContent, string(worktree))
+	return sides, nil
+}
+
+// stagedSides diffs the HEAD version of path against the index
+func stagedSides(path string) (*hunkSides, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	tree, err := headTree(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+
+	from, fromContent, err := treeFile(repo, tree, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from HEAD: %w", path, err)
+	}
+	to, toContent, err := indexFile(repo, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from the index: %w", path, err)
+	}
+	if to == nil || to.mode != filemode.Regular && to.mode != filemode.Executable {
+		return nil, ErrNotText
+	}
+	if isBinaryContent([]byte(fromContent)) || isBinaryContent([]byte(toContent)) {
+		return nil, ErrNotText
+	}
+
+	return &hunkSides{
+		repo:    repo,
+		path:    path,
+		inIndex: true,
+		inHead:  from != nil,
+		mode:    to.mode,
+		hunks:   computeHunks(fromContent, toContent),
+	}, nil
+}
+
+// GetUnstagedHunks returns the hunks between the index and the worktree
+// version of path, like git add -p shows them. Files not in the index are
+// compared with an empty file.
+func GetUnstagedHunks(path string) ([]Hunk, error) {
+	sides, err := unstagedSides(path)
+	if err != nil {
+		return nil, err
+	}
+	return sides.hunks.hunks, nil
+}
+
+// GetStagedHunks returns the hunks between HEAD and the index version of path
+func GetStagedHunks(path string) ([]Hunk, error) {
+	sides, err := stagedSides(path)
+	if err != nil {
+		return nil, err
+	}
+	return sides.hunks.hunks, nil
+}
+
+// StageLines stages the added and removed lines with the given IDs from the
+// hunks returned by GetUnstagedHunks
+func StageLines(path string, ids []int) error {
+	sides, err := unstagedSides(path)
+	if err != nil {
+		return err
+	}
+	selected := make(map[int]bool)
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	if useGitBinary() && sides.inIndex {
+		return applyPatch(sides.repo, sides.hunks.patch(path, selected, false), false)
+	}
+	return setIndexContent(sides.repo, path, sides.hunks.applyLines(selected), sides.mode)
+}
+
+// UnstageLines unstages the added and removed lines with the given IDs from
+// the hunks returned by GetStagedHunks. A new file is removed from the index
+// once nothing of it is left staged.
+func UnstageLines(path string, ids []int) error {
+	sides, err := stagedSides(path)
+	if err != nil {
+		return err
+	}
+	unstaged := make(map[int]bool)
+	for _, id := range ids {
+		unstaged[id] = true
+	}
+
+	if useGitBinary() && sides.inHead {
+		return applyPatch(sides.repo, sides.hunks.patch(path, unstaged, true), true)
+	}
+
+	// Keep every staged change that was not selected
+	keep := make(map[int]bool)
+	for _, hunk := range sides.hunks.hunks {
+		for _, id := range hunk.ChangeIDs() {
+			if !unstaged[id] {
+				keep[id] = true
+			}
+		}
+	}
+
+	if len(keep) == 0 && !sides.inHead {
+		idx, err := sides.repo.Storer.Index()
+		if err != nil {
+			return fmt.Errorf("failed to read index: %w", err)
+		}
+		if err := removeIndexEntry(idx, path); err != nil {
+			return fmt.Errorf("failed to unstage %s: %w", path, err)
+		}
+		return sides.repo.Storer.SetIndex(idx)
+	}
+	return setIndexContent(sides.repo, path, sides.hunks.applyLines(keep), sides.mode)
+}
+
+// setIndexContent stores content as the staged version of path
+func setIndexContent(repo *git.Repository, path, content string, mode filemode.FileMode) error {
+	hash, err := writeBlob(repo, content)
+	if err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	setIndexEntry(idx, path, hash, mode)
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// applyPatch applies a patch to the index with the git binary
+func applyPatch(repo *git.Repository, patch string, reverse bool) error {
+	args := []string{"apply", "--cached", "--recount"}
+	if reverse {
+		args = append(args, "--reverse")
+	}
+	cmd, err := gitCommand(repo, append(args, "-")...)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	cmd.Stdin = strings.NewReader(patch)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to apply patch: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeHunks(t *testing.T) {
+	from := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\ntwelve\n"
+	to := "ONE\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\ntwelve\nthirteen\n"
+
+	fh := computeHunks(from, to)
+	require.Len(t, fh.hunks, 2)
+	assert.Equal(t, "@@ -1,4 +1,4 @@\n-one\n+ONE\n two\n three\n four\n", fh.hunks[0].String())
+	assert.Equal(t, "@@ -10,3 +10,4 @@\n ten\n eleven\n twelve\n+thirteen\n", fh.hunks[1].String())
+
+	assert.Equal(t, from, fh.apply(nil))
+	assert.Equal(t, to, fh.apply(map[int]bool{0: true, 1: true}))
+	assert.Equal(t, "ONE\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\ntwelve\n", fh.apply(map[int]bool{0: true}))
+	assert.Equal(t, "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\ntwelve\nthirteen\n", fh.apply(map[int]bool{1: true}))
+
+	// Nearby changes share a hunk
+	fh = computeHunks("a\nb\nc\nd\n", "A\nb\nc\nD\n")
+	assert.Len(t, fh.hunks, 1)
+
+	// A missing final newline is marked
+	fh = computeHunks("a\n", "a\nb")
+	require.Len(t, fh.hunks, 1)
+	assert.Equal(t, "@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n", fh.hunks[0].String())
+}
+
+func TestStageAndUnstageLines(t *testing.T) {
+	for _, preferBinary := range []bool{false, true} {
+		t.Run(map[bool]string{false: "go-git", true: "git binary"}[preferBinary], func(t *testing.T) {
+			PreferGitBinary = preferBinary
+			defer func() { PreferGitBinary = false }()
+
+			// Setup test repository
+			tmpDir := setupTestRepo(t)
+			defer os.RemoveAll(tmpDir)
+
+			// Change to test repo directory
+			originalDir, err := os.Getwd()
+			require.NoError(t, err)
+			defer os.Chdir(originalDir)
+			err = os.Chdir(tmpDir)
+			require.NoError(t, err)
+
+			gitOutput := func(args ...string) string {
+				output, err := exec.Command("git", args...).Output()
+				require.NoError(t, err)
+				return string(output)
+			}
+
+			createTestFile(t, tmpDir, "fix.go", "package fix\n\nfunc Fix() int {\n\treturn 1\n}\n")
+			require.NoError(t, exec.Command("git", "add", "fix.go").Run())
+			require.NoError(t, exec.Command("git", "commit", "-m", "Initial commit").Run())
+
+			// A real fix mixed with a debug print in the same hunk
+			createTestFile(t, tmpDir, "fix.go", "package fix\n\nfunc Fix() int {\n\tprintln(\"debug\")\n\treturn 2\n}\n")
+
+			hunks, err := GetUnstagedHunks("fix.go")
+			require.NoError(t, err)
+			require.Len(t, hunks, 1)
+
+			var fixLines []int
+			for _, line := range hunks[0].Lines {
+				if line.IsChange() && !strings.Contains(line.Text, "debug") {
+					fixLines = append(fixLines, line.ID)
+				}
+			}
+			require.Len(t, fixLines, 2)
+
+			require.NoError(t, StageLines("fix.go", fixLines))
+			assert.Equal(t, "package fix\n\nfunc Fix() int {\n\treturn 2\n}\n", gitOutput("show", ":fix.go"))
+
+			hunks, err = GetUnstagedHunks("fix.go")
+			require.NoError(t, err)
+			require.Len(t, hunks, 1)
+			assert.Equal(t, []int{3}, hunks[0].ChangeIDs())
+
+			// Unstage the removal of the old return only, leaving both returns staged
+			staged, err := GetStagedHunks("fix.go")
+			require.NoError(t, err)
+			require.Len(t, staged, 1)
+			var removal int
+			for _, line := range staged[0].Lines {
+				if line.Op == '-' {
+					removal = line.ID
+				}
+			}
+			require.NoError(t, UnstageLines("fix.go", []int{removal}))
+			assert.Equal(t, "package fix\n\nfunc Fix() int {\n\treturn 1\n\treturn 2\n}\n", gitOutput("show", ":fix.go"))
+
+			staged, err = GetStagedHunks("fix.go")
+			require.NoError(t, err)
+			require.NoError(t, UnstageLines("fix.go", staged[0].ChangeIDs()))
+			assert.Empty(t, gitOutput("diff", "--cached"))
+		})
+	}
+}
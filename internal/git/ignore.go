@@ -0,0 +1,92 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// IgnoreFileName is the per-repository file listing paths whose content is
+// never sent to the LLM. It uses gitignore syntax.
+const IgnoreFileName = ".gitaiignore"
+
+// globalIgnorePath returns the location of the global ignore file,
+// ~/.config/gitai/ignore
+func globalIgnorePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gitai", "ignore"), nil
+}
+
+// loadIgnoreMatcher builds a matcher from the global ignore file and the
+// .gitaiignore in root. Patterns in .gitaiignore take precedence.
+func loadIgnoreMatcher(root string) gitignore.Matcher {
+	var patterns []gitignore.Pattern
+
+	if global, err := globalIgnorePath(); err == nil {
+		patterns = append(patterns, readIgnorePatterns(global)...)
+	}
+	patterns = append(patterns, readIgnorePatterns(filepath.Join(root, IgnoreFileName))...)
+
+	return gitignore.NewMatcher(patterns)
+}
+
+func readIgnorePatterns(path string) []gitignore.Pattern {
+	file, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Debugf("Failed to read ignore file %s: %v", path, err)
+		}
+		return nil
+	}
+	defer file.Close()
+
+	var patterns []gitignore.Pattern
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+
+	logger.Debugf("Loaded %d patterns from %s", len(patterns), path)
+	return patterns
+}
+
+// IgnoredPaths returns which of paths, relative to the repository root,
+// match the global ignore file or .gitaiignore. Their content must not be
+// sent to the LLM.
+func IgnoredPaths(paths []string) (map[string]bool, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	ignore := loadIgnoreMatcher(root)
+	ignored := make(map[string]bool)
+	for _, path := range paths {
+		if isIgnored(ignore, path) {
+			ignored[path] = true
+		}
+	}
+	return ignored, nil
+}
+
+// isIgnored reports whether path, relative to the repository root, matches
+// the ignore patterns
+func isIgnored(matcher gitignore.Matcher, path string) bool {
+	return matcher.Match(strings.Split(filepath.ToSlash(path), "/"), false)
+}
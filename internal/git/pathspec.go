@@ -0,0 +1,125 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// compilePathspec turns a glob into a regexp over repository paths. '*', '?'
+// and '[...]' match within one path component and '**' across directories.
+// A pattern naming a directory also matches everything below it.
+func compilePathspec(pattern string) (*regexp.Regexp, error) {
+	if pattern == "." {
+		return regexp.Compile(".*")
+	}
+
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[' && strings.IndexByte(pattern[i+1:], ']') > 0:
+			end := i + 1 + strings.IndexByte(pattern[i+1:], ']')
+			class := pattern[i+1 : end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("(?:/.*)?$")
+	return regexp.Compile(re.String())
+}
+
+// SelectChanges returns the changes whose paths match any of the pathspecs.
+// Pathspecs are relative to the current directory, like git's. As with git
+// add, a pathspec that matches no change is an error.
+func SelectChanges(changes []FileChange, pathspecs []string) ([]FileChange, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	prefix, err := cwdPrefix(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]bool, len(changes))
+	for _, pathspec := range pathspecs {
+		pattern := path.Join(prefix, filepath.ToSlash(pathspec))
+		if pattern == ".." || strings.HasPrefix(pattern, "../") {
+			return nil, fmt.Errorf("pathspec '%s' is outside the repository", pathspec)
+		}
+		re, err := compilePathspec(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pathspec '%s': %w", pathspec, err)
+		}
+
+		found := false
+		for i, change := range changes {
+			if re.MatchString(change.Path) {
+				matched[i] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("pathspec '%s' did not match any changed files", pathspec)
+		}
+	}
+
+	var selected []FileChange
+	for i, change := range changes {
+		if matched[i] {
+			selected = append(selected, change)
+		}
+	}
+	return selected, nil
+}
+
+// cwdPrefix returns the current directory relative to the worktree root,
+// "" at the root
+func cwdPrefix(repo *git.Repository) (string, error) {
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// Resolve symlinks on both sides, as temporary directories often are
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+
+	rel, err := filepath.Rel(root, cwd)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the current directory: %w", err)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePathspec(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"internal/**/*.go", "internal/git/changes.go", true},
+		{"internal/**/*.go", "internal/a/b/c.go", true},
+		{"internal/**/*.go", "internal/main.go", true},
+		{"internal/**/*.go", "cmd/main.go", false},
+		{"*.go", "main.go", true},
+		{"*.go", "internal/main.go", false},
+		{"internal", "internal/git/changes.go", true},
+		{"internal", "internals.go", false},
+		{"file?.txt", "file1.txt", true},
+		{"file[!0-9].txt", "file1.txt", false},
+		{"file[!0-9].txt", "fileA.txt", true},
+		{"docs/**", "docs/a/b.md", true},
+		{".", "anything/at/all", true},
+		{"a+b.txt", "a+b.txt", true},
+	}
+	for _, tt := range tests {
+		re, err := compilePathspec(tt.pattern)
+		require.NoError(t, err, tt.pattern)
+		assert.Equal(t, tt.match, re.MatchString(tt.path), "%s against %s", tt.pattern, tt.path)
+	}
+}
+
+func TestSelectChangesIsRelativeToCurrentDirectory(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "sub", "deep"), 0755))
+	require.NoError(t, os.Chdir(filepath.Join(tmpDir, "sub")))
+
+	changes := []FileChange{{Path: "root.go"}, {Path: "sub/a.go"}, {Path: "sub/deep/b.go"}, {Path: "sub/c.txt"}}
+
+	selected, err := SelectChanges(changes, []string{"**/*.go"})
+	require.NoError(t, err)
+	assert.Equal(t, []FileChange{{Path: "sub/a.go"}, {Path: "sub/deep/b.go"}}, selected)
+
+	selected, err = SelectChanges(changes, []string{"../root.go", "c.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []FileChange{{Path: "root.go"}, {Path: "sub/c.txt"}}, selected)
+
+	_, err = SelectChanges(changes, []string{"missing.go"})
+	assert.EqualError(t, err, "pathspec 'missing.go' did not match any changed files")
+
+	_, err = SelectChanges(changes, []string{"../../outside"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package git
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// renameThreshold is the minimum similarity, in percent, for an added file to
+// be paired with a rename or copy source. It matches git's default.
+const renameThreshold = 50
+
+// maxSimilaritySize is the largest blob compared line by line. Bigger files
+// are only paired when their content is identical.
+const maxSimilaritySize = 1024 * 1024
+
+// emptyBlobHash identifies empty files, which git never pairs as renames
+var emptyBlobHash = plumbing.ComputeHash(plumbing.BlobObject, nil)
+
+// renamePair describes an added file detected as a rename or copy
+type renamePair struct {
+	OldPath    string
+	Status     string
+	Similarity int
+}
+
+// detectRenames pairs staged additions with staged deletions (renames) and
+// with staged modifications (copies), like git diff --cached -M -C. Sources
+// are read from tree, the commit the status was computed against.
+// The result is keyed by the new path.
+func detectRenames(repo *git.Repository, tree *object.Tree, status git.Status) (map[string]renamePair, error) {
+	pairs := make(map[string]renamePair)
+
+	var added, deleted, modified []string
+	for path, fileStatus := range status {
+		switch fileStatus.Staging {
+		case git.Added:
+			added = append(added, path)
+		case git.Deleted:
+			deleted = append(deleted, path)
+		case git.Modified:
+			modified = append(modified, path)
+		}
+	}
+	if len(added) == 0 || len(deleted)+len(modified) == 0 {
+		return pairs, nil
+	}
+	sort.Strings(added)
+	sort.Strings(deleted)
+	sort.Strings(modified)
+
+	if tree == nil {
+		// Without a commit there is nothing to rename from
+		return pairs, nil
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	addedHashes := make(map[string]plumbing.Hash)
+	for _, path := range added {
+		if entry, err := idx.Entry(path); err == nil {
+			addedHashes[path] = entry.Hash
+		}
+	}
+
+	headHashes := make(map[string]plumbing.Hash)
+	for _, path := range append(append([]string{}, deleted...), modified...) {
+		if entry, err := tree.FindEntry(path); err == nil {
+			headHashes[path] = entry.Hash
+		}
+	}
+
+	// Exact renames first, as git does
+	used := make(map[string]bool)
+	for _, newPath := range added {
+		hash, ok := addedHashes[newPath]
+		if !ok || hash == emptyBlobHash {
+			continue
+		}
+		for _, oldPath := range deleted {
+			if !used[oldPath] && headHashes[oldPath] == hash {
+				pairs[newPath] = renamePair{OldPath: oldPath, Status: "renamed", Similarity: 100}
+				used[oldPath] = true
+				break
+			}
+		}
+	}
+
+	contents := newBlobCache(repo)
+	type candidate struct {
+		newPath, oldPath string
+		score            int
+	}
+
+	var candidates []candidate
+	for _, newPath := range added {
+		if _, ok := pairs[newPath]; ok {
+			continue
+		}
+		newContent, ok := contents.get(addedHashes[newPath])
+		if !ok {
+			continue
+		}
+		for _, oldPath := range append(append([]string{}, deleted...), modified...) {
+			if used[oldPath] {
+				continue
+			}
+			oldContent, ok := contents.get(headHashes[oldPath])
+			if !ok {
+				continue
+			}
+			if score := similarity(oldContent, newContent); score >= renameThreshold {
+				candidates = append(candidates, candidate{newPath: newPath, oldPath: oldPath, score: score})
+			}
+		}
+	}
+
+	// Best matches win; ties keep the path order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	isDeleted := make(map[string]bool)
+	for _, path := range deleted {
+		isDeleted[path] = true
+	}
+
+	for _, c := range candidates {
+		if _, ok := pairs[c.newPath]; ok {
+			continue
+		}
+
+		if isDeleted[c.oldPath] {
+			// A deleted file can only be renamed once
+			if used[c.oldPath] {
+				continue
+			}
+			used[c.oldPath] = true
+			pairs[c.newPath] = renamePair{OldPath: c.oldPath, Status: "renamed", Similarity: c.score}
+		} else {
+			pairs[c.newPath] = renamePair{OldPath: c.oldPath, Status: "copied", Similarity: c.score}
+		}
+		logger.Debugf("Detected %s %s -> %s (%d%%)", pairs[c.newPath].Status, c.oldPath, c.newPath, c.score)
+	}
+
+	return pairs, nil
+}
+
+// blobCache loads blob contents once for repeated comparisons
+type blobCache struct {
+	repo     *git.Repository
+	contents map[plumbing.Hash][]byte
+}
+
+func newBlobCache(repo *git.Repository) *blobCache {
+	return &blobCache{repo: repo, contents: make(map[plumbing.Hash][]byte)}
+}
+
+func (c *blobCache) get(hash plumbing.Hash) ([]byte, bool) {
+	if hash.IsZero() || hash == emptyBlobHash {
+		return nil, false
+	}
+	if content, ok := c.contents[hash]; ok {
+		return content, content != nil
+	}
+
+	content, err := readBlob(c.repo, hash, maxSimilaritySize)
+	if err != nil {
+		logger.Debugf("Skipping blob %s for rename detection: %v", hash, err)
+	}
+	c.contents[hash] = content
+	return content, content != nil
+}
+
+// readBlob returns the content of a blob, or nil if it is larger than limit
+func readBlob(repo *git.Repository, hash plumbing.Hash, limit int64) ([]byte, error) {
+	blob, err := object.GetBlob(repo.Storer, hash)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && blob.Size > limit {
+		return nil, nil
+	}
+
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// similarity scores how much of the larger of a and b is made of lines shared
+// with the other, from 0 to 100
+func similarity(a, b []byte) int {
+	if bytes.Equal(a, b) {
+		return 100
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int)
+	for _, line := range bytes.SplitAfter(a, []byte("\n")) {
+		counts[string(line)]++
+	}
+
+	common := 0
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if counts[string(line)] > 0 {
+			counts[string(line)]--
+			common += len(line)
+		}
+	}
+
+	return common * 100 / max(len(a), len(b))
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"os/exec"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// openRepository opens the repository containing the current directory,
+// searching parent directories like git does. Linked worktrees created with
+// git worktree add share the object database of their main repository.
+func openRepository() (*git.Repository, error) {
+	return git.PlainOpenWithOptions(".", &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+}
+
+// worktreeRoot returns the top-level directory of the repository's worktree.
+// All paths reported by this package are relative to it.
+func worktreeRoot(repo *git.Repository) (string, error) {
+	w, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return w.Filesystem.Root(), nil
+}
+
+// gitCommand builds a git binary invocation that runs in the worktree root,
+// so that root-relative paths resolve correctly from any subdirectory
+func gitCommand(repo *git.Repository, args ...string) (*exec.Cmd, error) {
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	return cmd, nil
+}
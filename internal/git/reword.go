@@ -0,0 +1,427 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// RangeCommit is a commit selected for rewording
+type RangeCommit struct {
+	Hash    string
+	Message string
+	Author  string
+}
+
+// ShortHash returns the abbreviated commit hash
+func (c RangeCommit) ShortHash() string {
+	if len(c.Hash) < 7 {
+		return c.Hash
+	}
+	return c.Hash[:7]
+}
+
+// Subject returns the first line of the commit message
+func (c RangeCommit) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return subject
+}
+
+// resolveRange resolves a revision range ending at HEAD. "A..B" and "A.."
+// are accepted, as is a bare "A" meaning A..HEAD. Like git, the range holds
+// the commits reachable from HEAD but not from A, so A may have moved on
+// since the branch point. It returns them oldest first.
+func resolveRange(repo *git.Repository, revRange string) ([]*object.Commit, error) {
+	baseRev, tipRev, isRange := strings.Cut(revRange, "..")
+	if strings.HasPrefix(tipRev, ".") {
+		return nil, fmt.Errorf("symmetric difference ranges are not supported: %s", revRange)
+	}
+	if !isRange || tipRev == "" {
+		tipRev = "HEAD"
+	}
+
+	base, err := repo.ResolveRevision(plumbing.Revision(baseRev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", baseRev, err)
+	}
+	tip, err := repo.ResolveRevision(plumbing.Revision(tipRev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", tipRev, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if *tip != head.Hash() {
+		return nil, fmt.Errorf("the range must end at HEAD: %s", revRange)
+	}
+
+	// Stop where HEAD's history joins A's
+	baseCommit, err := repo.CommitObject(*base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", baseRev, err)
+	}
+	tipCommit, err := repo.CommitObject(*tip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", tipRev, err)
+	}
+	bases, err := baseCommit.MergeBase(tipCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the merge base of %s and %s: %w", baseRev, tipRev, err)
+	}
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("%s and %s have no common ancestor", baseRev, tipRev)
+	}
+	stop := bases[0].Hash
+
+	var commits []*object.Commit
+	hash := *tip
+	for hash != stop {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+		if commit.NumParents() > 1 {
+			return nil, fmt.Errorf("cannot reword across merge commit %s", hash.String()[:7])
+		}
+		if commit.NumParents() == 0 {
+			return nil, fmt.Errorf("the merge base of %s and HEAD is not on HEAD's first-parent history", baseRev)
+		}
+		commits = append([]*object.Commit{commit}, commits...)
+		hash = commit.ParentHashes[0]
+	}
+
+	return commits, nil
+}
+
+// GetRangeCommits returns the commits in revRange, oldest first. The range
+// must end at HEAD and must not contain merges.
+func GetRangeCommits(revRange string) ([]RangeCommit, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	commits, err := resolveRange(repo, revRange)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]RangeCommit, len(commits))
+	for i, commit := range commits {
+		result[i] = RangeCommit{
+			Hash:    commit.Hash.String(),
+			Message: commit.Message,
+			Author:  fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email),
+		}
+	}
+	return result, nil
+}
+
+// GetCommitContent describes the changes a commit made to its parent in the
+// same format as GetStagedContent, with its current message as context
+func GetCommitContent(hash string, opts ContentOptions) (string, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit tree: %w", err)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return "", fmt.Errorf("failed to read parent commit: %w", err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to read parent tree: %w", err)
+		}
+	}
+
+	treeChanges, err := object.DiffTreeWithOptions(context.Background(), parentTree, tree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff commit %s: %w", hash, err)
+	}
+
+	changes, err := commitChanges(repo, treeChanges)
+	if err != nil {
+		return "", err
+	}
+
+	var content strings.Builder
+	content.WriteString("=== Current Commit Message ===\n\n")
+	content.WriteString(strings.TrimSpace(commit.Message) + "\n\n")
+	writeChangesSummary(&content, changes)
+	content.WriteString("\n=== Detailed Changes ===\n")
+
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	ignore := loadIgnoreMatcher(root)
+
+	for _, change := range changes {
+		if change.Status == "deleted" {
+			content.WriteString(fmt.Sprintf("\nDeleted file: %s\n", change.Path))
+			continue
+		}
+
+		if isIgnored(ignore, change.Path) {
+			logger.Debugf("Omitting content of %s matched by %s", change.Path, IgnoreFileName)
+			content.WriteString(fmt.Sprintf("\n=== %s ===\n(changed, content omitted)\n", change.Path))
+			continue
+		}
+
+		oldPath := change.OldPath
+		if oldPath == "" {
+			oldPath = change.Path
+		}
+		before, err := treeBlobInfo(repo, parentTree, oldPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to inspect %s: %w", change.Path, err)
+		}
+		after, err := treeBlobInfo(repo, tree, change.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to inspect %s: %w", change.Path, err)
+		}
+
+		err = writeChangeDetails(&content, change, before, after, opts, func() (string, error) {
+			from, fromContent, err := treeFile(repo, parentTree, oldPath)
+			if err != nil {
+				return "", err
+			}
+			to, toContent, err := treeFile(repo, tree, change.Path)
+			if err != nil {
+				return "", err
+			}
+			return encodeDiff(repo, from, to, fromContent, toContent)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return content.String(), nil
+}
+
+// commitChanges converts tree changes into the StagedChange form used for
+// prompts, computing the similarity of renames
+func commitChanges(repo *git.Repository, treeChanges object.Changes) ([]StagedChange, error) {
+	contents := newBlobCache(repo)
+
+	var changes []StagedChange
+	for _, tc := range treeChanges {
+		action, err := tc.Action()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read change: %w", err)
+		}
+
+		switch action {
+		case merkletrie.Insert:
+			changes = append(changes, StagedChange{Path: tc.To.Name, Status: "added"})
+		case merkletrie.Delete:
+			changes = append(changes, StagedChange{Path: tc.From.Name, Status: "deleted"})
+		case merkletrie.Modify:
+			if tc.From.Name == tc.To.Name {
+				changes = append(changes, StagedChange{Path: tc.To.Name, Status: "modified"})
+				continue
+			}
+
+			score := 100
+			if tc.From.TreeEntry.Hash != tc.To.TreeEntry.Hash {
+				oldContent, okOld := contents.get(tc.From.TreeEntry.Hash)
+				newContent, okNew := contents.get(tc.To.TreeEntry.Hash)
+				score = renameThreshold
+				if okOld && okNew {
+					score = similarity(oldContent, newContent)
+				}
+			}
+			changes = append(changes, StagedChange{
+				Path:       tc.To.Name,
+				OldPath:    tc.From.Name,
+				Status:     "renamed",
+				Similarity: score,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// treeBlobInfo returns the metadata of path in tree, or nil if it does not
+// exist there
+func treeBlobInfo(repo *git.Repository, tree *object.Tree, path string) (*blobInfo, error) {
+	if tree == nil {
+		return nil, nil
+	}
+	entry, err := tree.FindEntry(path)
+	if err != nil {
+		return nil, nil
+	}
+	return inspectBlob(repo, entry.Hash)
+}
+
+// RewordCommits rewrites commits, as returned by GetRangeCommits, with new
+// messages keyed by the original commit hash. Commits without a new message
+// keep theirs but are still rewritten when an earlier commit changed. Trees
+// and authors are kept; the committer becomes the current user, as with git
+// rebase. The branch is only updated if it still points at the last commit,
+// and the previous tip is saved as ORIG_HEAD. Only the signing fields of
+// opts apply. The new tip is returned.
+func RewordCommits(commits []RangeCommit, messages map[string]string, opts CommitOptions) (string, error) {
+	if len(commits) == 0 {
+		return "", fmt.Errorf("no commits to reword")
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	if head.Type() != plumbing.SymbolicReference {
+		return "", fmt.Errorf("cannot reword on a detached HEAD")
+	}
+	branch, err := repo.Storer.Reference(head.Target())
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", head.Target().Short(), err)
+	}
+
+	if branch.Hash().String() != commits[len(commits)-1].Hash {
+		return "", fmt.Errorf("%s has moved since the commits were listed", branch.Name().Short())
+	}
+
+	config, err := GetGitConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git config: %w", err)
+	}
+	if config.Name == "" || config.Email == "" {
+		return "", fmt.Errorf("git user.name and user.email must be set")
+	}
+
+	var signer git.Signer
+	signing := readSigningConfig(repo)
+	if (signing.Enabled || opts.Sign) && !opts.NoSign {
+		signer, err = newSigner(signing, config, opts.SigningKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to set up commit signing: %w", err)
+		}
+	}
+
+	committer := object.Signature{
+		Name:  config.Name,
+		Email: config.Email,
+		When:  time.Now(),
+	}
+
+	first, err := repo.CommitObject(plumbing.NewHash(commits[0].Hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", commits[0].ShortHash(), err)
+	}
+	if first.NumParents() != 1 {
+		return "", fmt.Errorf("cannot reword root or merge commit %s", commits[0].ShortHash())
+	}
+
+	parent := first.ParentHashes[0]
+	rewritten := false
+	for _, rc := range commits {
+		commit, err := repo.CommitObject(plumbing.NewHash(rc.Hash))
+		if err != nil {
+			return "", fmt.Errorf("failed to read commit %s: %w", rc.ShortHash(), err)
+		}
+
+		message, reworded := messages[rc.Hash]
+		if !reworded && !rewritten {
+			// Nothing before this commit changed, so it can be kept as is
+			parent = commit.Hash
+			continue
+		}
+		if reworded {
+			message = cleanupMessage(message)
+		}
+		if message == "" {
+			message = commit.Message
+		}
+
+		newCommit := &object.Commit{
+			Author:       commit.Author,
+			Committer:    committer,
+			Message:      message,
+			TreeHash:     commit.TreeHash,
+			ParentHashes: []plumbing.Hash{parent},
+			Encoding:     commit.Encoding,
+		}
+		if signer != nil {
+			if err := signCommit(newCommit, signer); err != nil {
+				return "", fmt.Errorf("failed to sign commit: %w", err)
+			}
+		}
+
+		obj := repo.Storer.NewEncodedObject()
+		if err := newCommit.Encode(obj); err != nil {
+			return "", fmt.Errorf("failed to encode commit: %w", err)
+		}
+		parent, err = repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			return "", fmt.Errorf("failed to write commit: %w", err)
+		}
+		logger.Debugf("Rewrote %s as %s", commit.Hash, parent)
+		rewritten = true
+	}
+
+	if !rewritten {
+		return branch.Hash().String(), nil
+	}
+
+	// Fails if the branch moved while the messages were being chosen
+	newRef := plumbing.NewHashReference(branch.Name(), parent)
+	if err := repo.Storer.CheckAndSetReference(newRef, branch); err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", branch.Name().Short(), err)
+	}
+
+	origHead := plumbing.NewHashReference(plumbing.ReferenceName("ORIG_HEAD"), branch.Hash())
+	if err := repo.Storer.SetReference(origHead); err != nil {
+		logger.Errorf("Failed to save ORIG_HEAD: %v", err)
+	}
+
+	return parent.String(), nil
+}
+
+// signCommit signs the commit's encoding without a signature, the same way
+// go-git signs new commits
+func signCommit(commit *object.Commit, signer git.Signer) error {
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return err
+	}
+	reader, err := encoded.Reader()
+	if err != nil {
+		return err
+	}
+	signature, err := signer.Sign(reader)
+	if err != nil {
+		return err
+	}
+	commit.PGPSignature = string(signature)
+	return nil
+}
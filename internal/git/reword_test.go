@@ -0,0 +1,115 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewordCommits(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	commit := func(file, content, message string) {
+		createTestFile(t, tmpDir, file, content)
+		require.NoError(t, exec.Command("git", "add", file).Run())
+		cmd := exec.Command("git", "commit", "-m", message)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Original Author", "GIT_AUTHOR_EMAIL=original@example.com")
+		require.NoError(t, cmd.Run())
+	}
+	gitOutput := func(args ...string) string {
+		output, err := exec.Command("git", args...).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(output))
+	}
+
+	commit("base.txt", "base\n", "Initial commit")
+	commit("one.txt", "one\n", "wip")
+	commit("two.txt", "two\n", "fix")
+	commit("three.txt", "three\n", "more")
+
+	commits, err := GetRangeCommits("HEAD~3")
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	assert.Equal(t, "wip", commits[0].Subject())
+	assert.Equal(t, "more", commits[2].Subject())
+
+	_, err = GetRangeCommits("HEAD~1..HEAD~1")
+	assert.Error(t, err)
+
+	content, err := GetCommitContent(commits[1].Hash, ContentOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "=== Current Commit Message ===\n\nfix\n")
+	assert.Contains(t, content, "two.txt (status: added)")
+	assert.Contains(t, content, "+two")
+	assert.NotContains(t, content, "one.txt")
+
+	originalTip := gitOutput("rev-parse", "HEAD")
+	tip, err := RewordCommits(commits, map[string]string{
+		commits[1].Hash: "feat: add the second file\n\nWith a body.",
+	}, CommitOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, tip, gitOutput("rev-parse", "HEAD"))
+	assert.Equal(t, originalTip, gitOutput("rev-parse", "ORIG_HEAD"))
+	assert.Equal(t, "more\nfeat: add the second file\nwip\nInitial commit", gitOutput("log", "--format=%s"))
+	assert.Equal(t, "With a body.", gitOutput("log", "-1", "--format=%b", "HEAD~1"))
+
+	// Untouched commits before the first reworded one keep their hashes
+	assert.Equal(t, commits[0].Hash, gitOutput("rev-parse", "HEAD~2"))
+	// Trees and authors are kept
+	assert.Equal(t, gitOutput("rev-parse", originalTip+"^{tree}"), gitOutput("rev-parse", "HEAD^{tree}"))
+	assert.Equal(t, "Original Author", gitOutput("log", "-1", "--format=%an", "HEAD~1"))
+	assert.Empty(t, gitOutput("status", "--porcelain"))
+
+	// The stale list no longer matches the branch
+	_, err = RewordCommits(commits, map[string]string{commits[0].Hash: "Add the first file"}, CommitOptions{})
+	assert.Error(t, err)
+}
+
+func TestGetRangeCommitsAfterBaseDiverged(t *testing.T) {
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	commit := func(file, message string) {
+		createTestFile(t, tmpDir, file, file+"\n")
+		require.NoError(t, exec.Command("git", "add", file).Run())
+		require.NoError(t, exec.Command("git", "commit", "-m", message).Run())
+	}
+
+	commit("base.txt", "Initial commit")
+	require.NoError(t, exec.Command("git", "branch", "-M", "main").Run())
+	require.NoError(t, exec.Command("git", "checkout", "-q", "-b", "feature").Run())
+	commit("one.txt", "wip")
+	commit("two.txt", "fix")
+
+	// main moves on after the branch point
+	require.NoError(t, exec.Command("git", "checkout", "-q", "main").Run())
+	commit("main.txt", "Unrelated change")
+	require.NoError(t, exec.Command("git", "checkout", "-q", "feature").Run())
+
+	for _, revRange := range []string{"main..HEAD", "main.."} {
+		commits, err := GetRangeCommits(revRange)
+		require.NoError(t, err, revRange)
+		require.Len(t, commits, 2, revRange)
+		assert.Equal(t, "wip", commits[0].Subject())
+		assert.Equal(t, "fix", commits[1].Subject())
+	}
+}
@@ -0,0 +1,223 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// Signature formats supported by gpg.format
+const (
+	signFormatOpenPGP = "openpgp"
+	signFormatSSH     = "ssh"
+	signFormatX509    = "x509"
+)
+
+// signingConfig holds the signing settings resolved from git config
+type signingConfig struct {
+	Enabled bool
+	Format  string
+	Key     string
+	Program string
+}
+
+// readSigningConfig reads commit.gpgsign, gpg.format, user.signingkey and the
+// signing program for the configured format from the resolved git config
+func readSigningConfig(repo *git.Repository) signingConfig {
+	cfg := signingConfig{
+		Enabled: getConfigBool(repo, "commit", "", "gpgsign"),
+		Format:  strings.ToLower(getConfigValue(repo, "gpg", "", "format")),
+		Key:     getConfigValue(repo, "user", "", "signingkey"),
+	}
+	if cfg.Format == "" {
+		cfg.Format = signFormatOpenPGP
+	}
+
+	switch cfg.Format {
+	case signFormatOpenPGP:
+		cfg.Program = getConfigValue(repo, "gpg", "openpgp", "program")
+		if cfg.Program == "" {
+			cfg.Program = getConfigValue(repo, "gpg", "", "program")
+		}
+		if cfg.Program == "" {
+			cfg.Program = "gpg"
+		}
+	case signFormatSSH:
+		cfg.Program = getConfigValue(repo, "gpg", "ssh", "program")
+		if cfg.Program == "" {
+			cfg.Program = "ssh-keygen"
+		}
+	case signFormatX509:
+		cfg.Program = getConfigValue(repo, "gpg", "x509", "program")
+		if cfg.Program == "" {
+			cfg.Program = "gpgsm"
+		}
+	}
+
+	return cfg
+}
+
+// newSigner returns the signer for the configured signature format.
+// OpenPGP signing is done in-process by go-git when keyFile points to an
+// armored private key; otherwise signing is delegated to the configured program.
+func newSigner(cfg signingConfig, identity *GitConfig, keyFile string) (git.Signer, error) {
+	switch cfg.Format {
+	case signFormatOpenPGP:
+		if keyFile != "" {
+			return newOpenPGPSigner(keyFile, cfg.Key)
+		}
+		key := cfg.Key
+		if key == "" {
+			key = fmt.Sprintf("%s <%s>", identity.Name, identity.Email)
+		}
+		return &commandSigner{program: cfg.Program, args: []string{"--status-fd=2", "-bsau", key}}, nil
+	case signFormatX509:
+		key := cfg.Key
+		if key == "" {
+			key = identity.Email
+		}
+		return &commandSigner{program: cfg.Program, args: []string{"--status-fd=2", "-bsau", key}}, nil
+	case signFormatSSH:
+		if cfg.Key == "" {
+			return nil, fmt.Errorf("user.signingkey must be set for SSH signing")
+		}
+		return &sshSigner{program: cfg.Program, key: cfg.Key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported gpg.format: %s", cfg.Format)
+	}
+}
+
+// commandSigner signs by piping the object to an external program and
+// reading a detached armored signature from its stdout
+type commandSigner struct {
+	program string
+	args    []string
+}
+
+func (s *commandSigner) Sign(message io.Reader) ([]byte, error) {
+	return runSigner(s.program, s.args, message)
+}
+
+// sshSigner signs with ssh-keygen -Y sign. Literal public keys are written to
+// a temporary file so the private key can be taken from ssh-agent.
+type sshSigner struct {
+	program string
+	key     string
+}
+
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	args := []string{"-Y", "sign", "-n", "git"}
+
+	literal := strings.TrimPrefix(s.key, "key::")
+	if literal != s.key || strings.HasPrefix(literal, "ssh-") || strings.HasPrefix(literal, "ecdsa-") {
+		file, err := os.CreateTemp("", "gitai-signingkey-*.pub")
+		if err != nil {
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		defer os.Remove(file.Name())
+
+		if _, err := file.WriteString(literal + "\n"); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		file.Close()
+
+		args = append(args, "-U", "-f", file.Name())
+	} else {
+		args = append(args, "-f", expandHome(s.key))
+	}
+
+	return runSigner(s.program, args, message)
+}
+
+func runSigner(program string, args []string, message io.Reader) ([]byte, error) {
+	logger.Debugf("Signing commit with: %s %s", program, strings.Join(args, " "))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(program, args...)
+	cmd.Stdin = message
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed to sign the data: %w: %s", program, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("%s returned an empty signature", program)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// newOpenPGPSigner loads an armored private key for go-git's built-in
+// OpenPGP signer. When keyID is set the matching entity is used.
+func newOpenPGPSigner(keyFile, keyID string) (git.Signer, error) {
+	file, err := os.Open(expandHome(keyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signing key: %w", err)
+	}
+	defer file.Close()
+
+	entities, err := openpgp.ReadArmoredKeyRing(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	var entity *openpgp.Entity
+	keyID = strings.ToUpper(strings.TrimPrefix(keyID, "0x"))
+	for _, e := range entities {
+		if e.PrivateKey == nil {
+			continue
+		}
+		if keyID == "" || strings.HasSuffix(strings.ToUpper(e.PrimaryKey.KeyIdString()), keyID) {
+			entity = e
+			break
+		}
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("no private key found in %s", keyFile)
+	}
+
+	if entity.PrivateKey.Encrypted {
+		passphrase := os.Getenv("GITAI_SIGNING_PASSPHRASE")
+		if passphrase == "" {
+			return nil, fmt.Errorf("signing key is encrypted: set GITAI_SIGNING_PASSPHRASE")
+		}
+		if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+		}
+	}
+
+	return &openPGPSigner{entity: entity}, nil
+}
+
+// openPGPSigner creates detached armored signatures in-process
+type openPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+func (s *openPGPSigner) Sign(message io.Reader) ([]byte, error) {
+	var b bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&b, s.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("failed to sign the data: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
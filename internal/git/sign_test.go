@@ -0,0 +1,100 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitConfig(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"config"}, args...)...)
+	cmd.Dir = dir
+	require.NoError(t, cmd.Run())
+}
+
+func TestCommitChangesSignsWithSSH(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	cmd := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyPath)
+	require.NoError(t, cmd.Run())
+
+	gitConfig(t, tmpDir, "commit.gpgsign", "true")
+	gitConfig(t, tmpDir, "gpg.format", "ssh")
+	gitConfig(t, tmpDir, "user.signingkey", keyPath)
+
+	createTestFile(t, tmpDir, "test.txt", "test content")
+	require.NoError(t, StageFile("test.txt"))
+	require.NoError(t, CommitChanges("Add test file", CommitOptions{}))
+
+	cmd = exec.Command("git", "cat-file", "-p", "HEAD")
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "gpgsig -----BEGIN SSH SIGNATURE-----")
+}
+
+func TestCommitChangesSignsWithOpenPGPKeyFile(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	entity, err := openpgp.NewEntity("Test User", "", "test@example.com", nil)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "key.asc")
+	file, err := os.Create(keyPath)
+	require.NoError(t, err)
+	w, err := armor.Encode(file, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+	require.NoError(t, file.Close())
+
+	createTestFile(t, tmpDir, "test.txt", "test content")
+	require.NoError(t, StageFile("test.txt"))
+
+	// Without commit.gpgsign the commit is only signed when requested
+	require.NoError(t, CommitChanges("Add test file", CommitOptions{Sign: true, SigningKeyFile: keyPath}))
+
+	cmd := exec.Command("git", "cat-file", "-p", "HEAD")
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "gpgsig -----BEGIN PGP SIGNATURE-----")
+
+	// --no-gpg-sign wins over commit.gpgsign
+	gitConfig(t, tmpDir, "commit.gpgsign", "true")
+	createTestFile(t, tmpDir, "other.txt", "other content")
+	require.NoError(t, StageFile("other.txt"))
+	require.NoError(t, CommitChanges("Add other file", CommitOptions{NoSign: true}))
+
+	cmd = exec.Command("git", "cat-file", "-p", "HEAD")
+	output, err = cmd.Output()
+	require.NoError(t, err)
+	assert.NotContains(t, string(output), "gpgsig")
+}
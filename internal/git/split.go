@@ -0,0 +1,293 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// SplitUnit is an independently committable part of the staged changes: one
+// hunk of a modified text file, or a whole file otherwise
+type SplitUnit struct {
+	// ID numbers the units from 1 in the prompt
+	ID      int
+	Path    string
+	OldPath string
+	Status  string
+	// Hunk is the unit's hunk number in the file, from 1, and Hunks the
+	// number of hunks in the file. Both are 0 for whole-file units.
+	Hunk  int
+	Hunks int
+
+	file *splitFile
+}
+
+// String describes the unit for display
+func (u SplitUnit) String() string {
+	if u.Hunks > 0 {
+		return fmt.Sprintf("%s (%s, hunk %d/%d)", displayPath(u.OldPath, u.Path), u.Status, u.Hunk, u.Hunks)
+	}
+	return fmt.Sprintf("%s (%s)", displayPath(u.OldPath, u.Path), u.Status)
+}
+
+// splitSide is one version of an index entry; nil means the path is absent
+type splitSide struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+}
+
+// splitEntry is an index path touched by a split file with its HEAD and
+// staged versions
+type splitEntry struct {
+	path   string
+	base   *splitSide
+	target *splitSide
+}
+
+// splitFile is a staged file change. Renames touch two index paths. Files
+// split into hunks have a single entry present on both sides.
+type splitFile struct {
+	entries []splitEntry
+	hunks   *fileHunks
+}
+
+// GetSplitUnits breaks the staged changes into units and describes them for
+// the prompt. Modified text files are split into hunks; added, deleted,
+// renamed, binary, ignored and oversized files are whole units.
+func GetSplitUnits(opts ContentOptions) ([]SplitUnit, string, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	tree, err := headTree(repo)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read index: %w", err)
+	}
+
+	status, err := indexStatus(repo, tree)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to compare the index with HEAD: %w", err)
+	}
+	renames, err := detectRenames(repo, tree, status)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to detect renames: %w", err)
+	}
+
+	renamedFrom := make(map[string]bool)
+	for _, pair := range renames {
+		if pair.Status == "renamed" {
+			renamedFrom[pair.OldPath] = true
+		}
+	}
+
+	var paths []string
+	for path := range status {
+		if !renamedFrom[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	ignore := loadIgnoreMatcher(root)
+
+	maxFileSize := opts.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = DefaultMaxFileSize
+	}
+
+	treeSide := func(path string) *splitSide {
+		if tree == nil {
+			return nil
+		}
+		entry, err := tree.FindEntry(path)
+		if err != nil {
+			return nil
+		}
+		return &splitSide{hash: entry.Hash, mode: entry.Mode}
+	}
+	indexSide := func(path string) *splitSide {
+		entry, err := idx.Entry(path)
+		if err != nil {
+			return nil
+		}
+		return &splitSide{hash: entry.Hash, mode: entry.Mode}
+	}
+
+	var units []SplitUnit
+	var content strings.Builder
+	content.WriteString("=== Staged Units ===\n")
+
+	addUnit := func(unit SplitUnit, body string) {
+		unit.ID = len(units) + 1
+		units = append(units, unit)
+		content.WriteString(fmt.Sprintf("\n=== Unit %d: %s ===\n%s", unit.ID, unit, body))
+	}
+
+	for _, path := range paths {
+		change := StagedChange{Path: path, Status: statusToString(status[path].Staging)}
+		file := &splitFile{entries: []splitEntry{{path: path, base: treeSide(path), target: indexSide(path)}}}
+		if pair, ok := renames[path]; ok {
+			change.OldPath = pair.OldPath
+			change.Status = pair.Status
+			change.Similarity = pair.Similarity
+			if pair.Status == "renamed" {
+				file.entries = append(file.entries, splitEntry{path: pair.OldPath, base: treeSide(pair.OldPath)})
+			}
+		}
+		unit := SplitUnit{Path: path, OldPath: change.OldPath, Status: change.Status, file: file}
+
+		if change.Status == "deleted" {
+			addUnit(unit, "(file deleted)\n")
+			continue
+		}
+		if isIgnored(ignore, path) {
+			addUnit(unit, "(changed, content omitted)\n")
+			continue
+		}
+
+		before, after, err := stagedBlobInfo(repo, tree, change.OldPath, path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to inspect %s: %w", path, err)
+		}
+
+		entry := file.entries[0]
+		splittable := change.Status == "modified" && entry.base.mode == entry.target.mode &&
+			!isBinaryChange(before, after) && before.Size <= maxFileSize && after.Size <= maxFileSize
+		if splittable {
+			hunks, err := stagedHunks(repo, entry.base.hash, entry.target.hash)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to diff %s: %w", path, err)
+			}
+			if len(hunks.hunks) > 0 {
+				file.hunks = hunks
+				for i, hunk := range hunks.hunks {
+					text := hunk.String()
+					if opts.Redactor != nil {
+						text = opts.Redactor.Redact(path, text)
+					}
+					unit.Hunk, unit.Hunks = i+1, len(hunks.hunks)
+					addUnit(unit, text)
+				}
+				continue
+			}
+		}
+
+		_, body, err := changeDetails(change, before, after, opts, func() (string, error) {
+			return stagedDiff(repo, tree, change.OldPath, path)
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		addUnit(unit, body)
+	}
+
+	return units, content.String(), nil
+}
+
+// stagedHunks splits the change between two blobs into hunks
+func stagedHunks(repo *git.Repository, from, to plumbing.Hash) (*fileHunks, error) {
+	fromContent, err := readBlob(repo, from, 0)
+	if err != nil {
+		return nil, err
+	}
+	toContent, err := readBlob(repo, to, 0)
+	if err != nil {
+		return nil, err
+	}
+	return computeHunks(string(fromContent), string(toContent)), nil
+}
+
+// StageSplitUnits sets the index so that, compared with the HEAD the units
+// were computed against, exactly the units with the given IDs are staged.
+// Passing every ID restores the original staging area.
+func StageSplitUnits(units []SplitUnit, ids []int) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	wanted := make(map[int]bool)
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	// Collect the selected hunks of each file
+	var files []*splitFile
+	selected := make(map[*splitFile]map[int]bool)
+	for _, unit := range units {
+		if _, ok := selected[unit.file]; !ok {
+			files = append(files, unit.file)
+			selected[unit.file] = make(map[int]bool)
+		}
+		if wanted[unit.ID] {
+			selected[unit.file][unit.Hunk-1] = true
+		}
+	}
+
+	for _, file := range files {
+		hunks := selected[file]
+		for _, entry := range file.entries {
+			side := entry.base
+			switch {
+			case file.hunks == nil && len(hunks) > 0,
+				file.hunks != nil && len(hunks) == len(file.hunks.hunks):
+				side = entry.target
+			case file.hunks != nil && len(hunks) > 0:
+				hash, err := writeBlob(repo, file.hunks.apply(hunks))
+				if err != nil {
+					return fmt.Errorf("failed to write partial %s: %w", entry.path, err)
+				}
+				side = &splitSide{hash: hash, mode: entry.base.mode}
+			}
+
+			if side == nil {
+				if err := removeIndexEntry(idx, entry.path); err != nil {
+					return fmt.Errorf("failed to unstage %s: %w", entry.path, err)
+				}
+				continue
+			}
+			setIndexEntry(idx, entry.path, side.hash, side.mode)
+		}
+	}
+
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// writeBlob stores content in the object database
+func writeBlob(repo *git.Repository, content string) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
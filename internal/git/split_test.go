@@ -0,0 +1,66 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitUnits(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	gitOutput := func(args ...string) string {
+		output, err := exec.Command("git", args...).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(output))
+	}
+
+	lines := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	createTestFile(t, tmpDir, "list.txt", lines)
+	createTestFile(t, tmpDir, "old.txt", "to be removed\n")
+	require.NoError(t, exec.Command("git", "add", ".").Run())
+	require.NoError(t, exec.Command("git", "commit", "-m", "Initial commit").Run())
+
+	createTestFile(t, tmpDir, "list.txt", strings.Replace(strings.Replace(lines, "one", "ONE", 1), "ten", "TEN", 1))
+	createTestFile(t, tmpDir, "new.txt", "new file\n")
+	require.NoError(t, exec.Command("git", "add", "-A").Run())
+	require.NoError(t, exec.Command("git", "rm", "-q", "old.txt").Run())
+
+	units, content, err := GetSplitUnits(ContentOptions{})
+	require.NoError(t, err)
+	require.Len(t, units, 4)
+	assert.Equal(t, "list.txt (modified, hunk 1/2)", units[0].String())
+	assert.Equal(t, "list.txt (modified, hunk 2/2)", units[1].String())
+	assert.Equal(t, "new.txt (added)", units[2].String())
+	assert.Equal(t, "old.txt (deleted)", units[3].String())
+	assert.Contains(t, content, "=== Unit 2: list.txt (modified, hunk 2/2) ===\n@@ -7,4 +7,4 @@")
+
+	// Commit the second hunk and the new file first
+	require.NoError(t, StageSplitUnits(units, []int{2, 3}))
+	assert.Equal(t, "M\tlist.txt\nA\tnew.txt", gitOutput("diff", "--cached", "--name-status"))
+	require.NoError(t, CommitChanges("Capitalize ten and add new file", CommitOptions{}))
+	assert.Equal(t, "one", strings.SplitN(gitOutput("show", "HEAD:list.txt"), "\n", 2)[0])
+	assert.Contains(t, gitOutput("show", "HEAD:list.txt"), "TEN")
+
+	// Then the rest, leaving the deletion staged
+	require.NoError(t, StageSplitUnits(units, []int{2, 3, 1}))
+	require.NoError(t, CommitChanges("Capitalize one", CommitOptions{}))
+	assert.Equal(t, strings.TrimSpace(strings.Replace(strings.Replace(lines, "one", "ONE", 1), "ten", "TEN", 1)), gitOutput("show", "HEAD:list.txt"))
+
+	require.NoError(t, StageSplitUnits(units, []int{1, 2, 3, 4}))
+	assert.Equal(t, "D\told.txt", gitOutput("diff", "--cached", "--name-status"))
+	assert.Empty(t, gitOutput("diff", "--name-status"))
+}
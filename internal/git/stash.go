@@ -0,0 +1,25 @@
+package git
+
+import (
+	"fmt"
+)
+
+// StashFiles stashes the staged and unstaged changes of paths, including
+// untracked files, like git stash push. go-git cannot write stashes, so this
+// always needs the git binary.
+func StashFiles(paths []string, message string) error {
+	if !hasGitBinary() {
+		return fmt.Errorf("stashing requires the git binary")
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	args := append([]string{"stash", "push", "--include-untracked", "--message", message, "--"}, paths...)
+	if err := runPathCommand(repo, args...); err != nil {
+		return fmt.Errorf("failed to stash changes: %w", err)
+	}
+	return nil
+}
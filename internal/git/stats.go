@@ -0,0 +1,87 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffStat counts the lines a staged file adds and removes
+type DiffStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	// Binary is set for binary files, whose lines are not counted
+	Binary bool
+}
+
+// GetStagedDiffStats returns the line counts of the staged changes, like
+// git diff --cached --numstat. With amend, the changes of HEAD are included.
+func GetStagedDiffStats(amend bool) ([]DiffStat, error) {
+	var changes []StagedChange
+	var err error
+	if amend {
+		changes, err = GetAmendChanges()
+	} else {
+		changes, err = GetStagedChanges()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged changes: %w", err)
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	var base *diffBase
+	if amend {
+		base, err = amendBase(repo)
+	} else {
+		base, err = headBase(repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]DiffStat, 0, len(changes))
+	for _, change := range changes {
+		stat := DiffStat{Path: change.DisplayPath()}
+
+		before, after, err := stagedBlobInfo(repo, base.tree, change.OldPath, change.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", change.Path, err)
+		}
+		if isBinaryChange(before, after) {
+			stat.Binary = true
+			stats = append(stats, stat)
+			continue
+		}
+
+		diff, err := stagedDiff(repo, base.tree, change.OldPath, change.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diff for %s: %w", change.Path, err)
+		}
+		stat.Added, stat.Deleted = countDiffLines(diff)
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// countDiffLines counts the added and removed lines in the hunks of a
+// unified diff
+func countDiffLines(diff string) (added, deleted int) {
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			inHunk = false
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		}
+	}
+	return added, deleted
+}
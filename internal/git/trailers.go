@@ -0,0 +1,239 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/ozankasikci/gitai/internal/logger"
+)
+
+// maxAuthorHistory limits how many commits are walked when collecting
+// co-author candidates from the repository history
+const maxAuthorHistory = 1000
+
+// Trailer is a single "Key: value" line in a commit message trailer block
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return fmt.Sprintf("%s: %s", t.Key, t.Value)
+}
+
+var trailerLineRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)\s*:\s*(.*)$`)
+
+// ParseTrailer parses a trailer given either as "Key: value" or "key=value",
+// the two forms accepted by git interpret-trailers
+func ParseTrailer(s string) (Trailer, error) {
+	s = strings.TrimSpace(s)
+	sep := strings.IndexAny(s, ":=")
+	if sep <= 0 {
+		return Trailer{}, fmt.Errorf("invalid trailer %q: expected \"Key: value\"", s)
+	}
+
+	key := strings.TrimSpace(s[:sep])
+	value := strings.TrimSpace(s[sep+1:])
+	if !trailerLineRegex.MatchString(key+": x") || value == "" {
+		return Trailer{}, fmt.Errorf("invalid trailer %q: expected \"Key: value\"", s)
+	}
+
+	return Trailer{Key: key, Value: value}, nil
+}
+
+// AppendTrailers appends trailers to message using git's trailer format.
+// If the message already ends with a trailer block the new trailers are added
+// to it, otherwise a blank line separates them from the body. Trailers that
+// are already present with the same value are not duplicated.
+func AppendTrailers(message string, trailers []Trailer) string {
+	message = strings.TrimRight(message, "\n ")
+	if len(trailers) == 0 {
+		return message + "\n"
+	}
+
+	lines := strings.Split(message, "\n")
+
+	// Find the start of the last paragraph, skipping the subject line
+	start := len(lines)
+	for start > 1 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+
+	hasTrailerBlock := start > 1 && start < len(lines)
+	existing := make(map[string]bool)
+	for _, line := range lines[start:] {
+		if !trailerLineRegex.MatchString(line) {
+			hasTrailerBlock = false
+			break
+		}
+		existing[strings.ToLower(line)] = true
+	}
+	if !hasTrailerBlock {
+		existing = make(map[string]bool)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(message)
+	if !hasTrailerBlock {
+		builder.WriteString("\n")
+	}
+
+	for _, trailer := range trailers {
+		line := trailer.String()
+		if existing[strings.ToLower(line)] {
+			continue
+		}
+		existing[strings.ToLower(line)] = true
+		builder.WriteString("\n" + line)
+	}
+	builder.WriteString("\n")
+
+	return builder.String()
+}
+
+// SignoffTrailer returns the Signed-off-by trailer for the configured identity
+func SignoffTrailer(config *GitConfig) Trailer {
+	return Trailer{
+		Key:   "Signed-off-by",
+		Value: fmt.Sprintf("%s <%s>", config.Name, config.Email),
+	}
+}
+
+// CoAuthorTrailer returns a Co-authored-by trailer for the given identity
+func CoAuthorTrailer(identity string) Trailer {
+	return Trailer{Key: "Co-authored-by", Value: identity}
+}
+
+// ResolveCoAuthor expands a co-author given as "Name <email>", a bare email or
+// a partial name into a full identity using the known authors of the repository
+func ResolveCoAuthor(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "<") && strings.HasSuffix(value, ">") {
+		return value, nil
+	}
+
+	authors, err := GetKnownAuthors()
+	if err != nil {
+		return "", err
+	}
+
+	needle := strings.ToLower(value)
+	var matches []string
+	for _, author := range authors {
+		if strings.Contains(strings.ToLower(author), needle) {
+			matches = append(matches, author)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("unknown co-author %q: use \"Name <email>\"", value)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("ambiguous co-author %q matches: %s", value, strings.Join(matches, ", "))
+	}
+}
+
+// GetKnownAuthors returns the identities found in .mailmap and the repository
+// history, formatted as "Name <email>" and sorted alphabetically
+func GetKnownAuthors() ([]string, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	// Canonical identities keyed by lower-cased email
+	identities := make(map[string]string)
+
+	root, err := worktreeRoot(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	mailmap, err := readMailmap(filepath.Join(root, ".mailmap"))
+	if err != nil {
+		logger.Debugf("No .mailmap found: %v", err)
+	}
+	for email, identity := range mailmap {
+		identities[email] = identity
+	}
+
+	commits, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		// An empty repository has no history to walk
+		logger.Debugf("Failed to read history: %v", err)
+	} else {
+		count := 0
+		err = commits.ForEach(func(c *object.Commit) error {
+			if count >= maxAuthorHistory {
+				return storer.ErrStop
+			}
+			count++
+
+			email := strings.ToLower(c.Author.Email)
+			if _, ok := identities[email]; !ok && email != "" {
+				identities[email] = fmt.Sprintf("%s <%s>", c.Author.Name, c.Author.Email)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	authors := make([]string, 0, len(identities))
+	for _, identity := range identities {
+		if !seen[identity] {
+			seen[identity] = true
+			authors = append(authors, identity)
+		}
+	}
+	sort.Strings(authors)
+
+	return authors, nil
+}
+
+var (
+	mailmapIdentityRegex = regexp.MustCompile(`^\s*([^<#]*?)\s*<([^>]+)>`)
+	mailmapEmailRegex    = regexp.MustCompile(`<([^>]+)>`)
+)
+
+// readMailmap maps every email in a .mailmap file, lower-cased, to the
+// canonical (first) identity on its line
+func readMailmap(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mailmap := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		match := mailmapIdentityRegex.FindStringSubmatch(line)
+		if match == nil || match[1] == "" {
+			continue
+		}
+		identity := fmt.Sprintf("%s <%s>", match[1], match[2])
+		for _, email := range mailmapEmailRegex.FindAllStringSubmatch(line, -1) {
+			mailmap[strings.ToLower(email[1])] = identity
+		}
+	}
+
+	return mailmap, scanner.Err()
+}
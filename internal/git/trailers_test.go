@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrailer(t *testing.T) {
+	trailer, err := ParseTrailer("Reviewed-by: Jane Doe <jane@example.com>")
+	assert.NoError(t, err)
+	assert.Equal(t, Trailer{Key: "Reviewed-by", Value: "Jane Doe <jane@example.com>"}, trailer)
+
+	trailer, err = ParseTrailer("Ticket=PROJ-123")
+	assert.NoError(t, err)
+	assert.Equal(t, Trailer{Key: "Ticket", Value: "PROJ-123"}, trailer)
+
+	_, err = ParseTrailer("not a trailer")
+	assert.Error(t, err)
+
+	_, err = ParseTrailer("Empty:")
+	assert.Error(t, err)
+}
+
+func TestAppendTrailers(t *testing.T) {
+	signoff := Trailer{Key: "Signed-off-by", Value: "Test User <test@example.com>"}
+
+	// Subject only gets a blank line before the trailer block
+	assert.Equal(t,
+		"Add feature\n\nSigned-off-by: Test User <test@example.com>\n",
+		AppendTrailers("Add feature", []Trailer{signoff}))
+
+	// An existing trailer block is extended
+	assert.Equal(t,
+		"Add feature\n\nBody text\n\nTicket: PROJ-1\nSigned-off-by: Test User <test@example.com>\n",
+		AppendTrailers("Add feature\n\nBody text\n\nTicket: PROJ-1\n", []Trailer{signoff}))
+
+	// Duplicate trailers are not added twice
+	assert.Equal(t,
+		"Add feature\n\nSigned-off-by: Test User <test@example.com>\n",
+		AppendTrailers("Add feature\n\nSigned-off-by: Test User <test@example.com>", []Trailer{signoff}))
+
+	// A body paragraph that merely contains a colon is not a trailer block
+	assert.Equal(t,
+		"Add feature\n\nNote: this is prose\nand more prose\n\nSigned-off-by: Test User <test@example.com>\n",
+		AppendTrailers("Add feature\n\nNote: this is prose\nand more prose", []Trailer{signoff}))
+}
+
+func TestCommitChangesWithTrailers(t *testing.T) {
+	// Setup test repository
+	tmpDir := setupTestRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Change to test repo directory
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer os.Chdir(originalDir)
+	err = os.Chdir(tmpDir)
+	require.NoError(t, err)
+
+	createTestFile(t, tmpDir, ".mailmap", "Jane Doe <jane@example.com> <jdoe@old.example.com>\n")
+	createTestFile(t, tmpDir, "test.txt", "test content")
+	require.NoError(t, StageFile("test.txt"))
+
+	err = CommitChanges("Add test file", CommitOptions{
+		Signoff:   true,
+		CoAuthors: []string{"jane"},
+		Trailers:  []string{"Ticket: PROJ-1"},
+	})
+	require.NoError(t, err)
+
+	cmd := exec.Command("git", "log", "-1", "--format=%B")
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t,
+		"Add test file\n\nTicket: PROJ-1\nCo-authored-by: Jane Doe <jane@example.com>\nSigned-off-by: Test User <test@example.com>\n\n",
+		string(output))
+}
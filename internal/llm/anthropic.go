@@ -18,12 +18,31 @@ type CommitSuggestion struct {
 	Explanation string
 }
 
+// CommitGroup is a logical commit proposed when splitting staged changes.
+// Units are the IDs of the staged units it contains.
+type CommitGroup struct {
+	Message     string
+	Explanation string
+	Units       []int
+}
+
+// Usage counts the tokens a client has used across its requests
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64
+}
+
 type CommitMessageGenerator interface {
 	GenerateCommitSuggestions(changes string) ([]CommitSuggestion, error)
+	GenerateCommitGroups(units string) ([]CommitGroup, error)
+	// Chat sends a conversation and returns the text of the reply
+	Chat(turns []Turn) (string, error)
+	Usage() Usage
 }
 
 type AnthropicClient struct {
 	client *anthropic.Client
+	usage  Usage
 }
 
 type SuggestionsMsg struct {
@@ -67,21 +86,62 @@ func (c *AnthropicClient) GenerateCommitSuggestions(changes string) ([]CommitSug
 	prompt := buildPrompt(formattedChanges)
 
 	logger.Debugf("\n=== Final formatted changes ===\n%s\n", formattedChanges)
-	logger.Debugf("\n=== Full prompt being sent to LLM ===\n%s\n", prompt)
+
+	responseText, err := c.complete(prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := parseResponse(responseText)
+	for i, suggestion := range suggestions {
+		logger.Debugf("Suggestion %d:\nMessage: %s\nExplanation: %s\n",
+			i+1, suggestion.Message, suggestion.Explanation)
+	}
+
+	return suggestions, nil
+}
+
+func (c *AnthropicClient) GenerateCommitGroups(units string) ([]CommitGroup, error) {
+	responseText, err := c.complete(buildSplitPrompt(units))
+	if err != nil {
+		return nil, err
+	}
+	return parseGroups(responseText), nil
+}
+
+func (c *AnthropicClient) Usage() Usage {
+	return c.usage
+}
+
+// complete sends a single-turn prompt and returns the text of the reply
+func (c *AnthropicClient) complete(prompt string) (string, error) {
+	return c.Chat([]Turn{{Role: RoleUser, Content: prompt}})
+}
+
+func (c *AnthropicClient) Chat(turns []Turn) (string, error) {
+	var messages []anthropic.MessageParam
+	for _, turn := range turns {
+		logger.Debugf("\n=== %s turn being sent to LLM ===\n%s\n", turn.Role, turn.Content)
+		if turn.Role == RoleAssistant {
+			messages = append(messages, anthropic.NewAssistantMessage(anthropic.NewTextBlock(turn.Content)))
+		} else {
+			messages = append(messages, anthropic.NewUserMessage(anthropic.NewTextBlock(turn.Content)))
+		}
+	}
 
 	cfg := config.Get()
 	msg, err := c.client.Messages.New(context.Background(), anthropic.MessageNewParams{
 		Model:     anthropic.F(anthropic.Model(cfg.LLM.Anthropic.Model)),
 		MaxTokens: anthropic.F(cfg.LLM.Anthropic.MaxTokens),
-		Messages: anthropic.F([]anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
-		}),
+		Messages:  anthropic.F(messages),
 	})
 
 	if err != nil {
 		logger.Errorf("Error from LLM: %v", err)
-		return nil, fmt.Errorf("failed to generate commit message: %w", err)
+		return "", fmt.Errorf("failed to generate commit message: %w", err)
 	}
+	c.usage.InputTokens += msg.Usage.InputTokens
+	c.usage.OutputTokens += msg.Usage.OutputTokens
 
 	var responseText string
 	for _, content := range msg.Content {
@@ -95,14 +155,8 @@ func (c *AnthropicClient) GenerateCommitSuggestions(changes string) ([]CommitSug
 
 	if responseText == "" {
 		logger.Errorf("No text content found in LLM response")
-		return nil, fmt.Errorf("no text content in response")
+		return "", fmt.Errorf("no text content in response")
 	}
 
-	suggestions := parseResponse(responseText)
-	for i, suggestion := range suggestions {
-		logger.Debugf("Suggestion %d:\nMessage: %s\nExplanation: %s\n",
-			i+1, suggestion.Message, suggestion.Explanation)
-	}
-
-	return suggestions, nil
+	return responseText, nil
 }
@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Turn is one message of a conversation with the provider
+type Turn struct {
+	Role    string
+	Content string
+}
+
+// SuggestionChat is a conversation about one set of changes, so that
+// follow-up requests such as "shorter" are answered with the earlier
+// suggestions in view
+type SuggestionChat struct {
+	client CommitMessageGenerator
+	turns  []Turn
+}
+
+// NewSuggestionChat starts a conversation from suggestions already
+// generated for changes
+func NewSuggestionChat(client CommitMessageGenerator, changes string, suggestions []CommitSuggestion) *SuggestionChat {
+	return &SuggestionChat{
+		client: client,
+		turns: []Turn{
+			{Role: RoleUser, Content: buildPrompt(changes)},
+			{Role: RoleAssistant, Content: formatSuggestions(suggestions)},
+		},
+	}
+}
+
+// Regenerate asks for a new set of suggestions
+func (c *SuggestionChat) Regenerate() ([]CommitSuggestion, error) {
+	return c.ask(regeneratePrompt)
+}
+
+// More asks for further suggestions, different from all earlier ones
+func (c *SuggestionChat) More() ([]CommitSuggestion, error) {
+	return c.ask(morePrompt)
+}
+
+// Refine asks for suggestions revised according to feedback, such as
+// "shorter" or "mention the migration"
+func (c *SuggestionChat) Refine(feedback string) ([]CommitSuggestion, error) {
+	return c.ask(buildFeedbackPrompt(feedback))
+}
+
+// ask sends request as the next turn. A failed request is left out of the
+// conversation.
+func (c *SuggestionChat) ask(request string) ([]CommitSuggestion, error) {
+	turns := append(c.turns[:len(c.turns):len(c.turns)], Turn{Role: RoleUser, Content: request})
+	reply, err := c.client.Chat(turns)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := parseResponse(reply)
+	if len(suggestions) == 0 {
+		return nil, fmt.Errorf("no suggestions found in the response")
+	}
+	c.turns = append(turns, Turn{Role: RoleAssistant, Content: reply})
+	return suggestions, nil
+}
+
+// formatSuggestions renders suggestions in the format the prompts ask for
+func formatSuggestions(suggestions []CommitSuggestion) string {
+	var builder strings.Builder
+	for i, suggestion := range suggestions {
+		builder.WriteString(fmt.Sprintf("%d - %s\n", i+1, suggestion.Message))
+		if suggestion.Explanation != "" {
+			builder.WriteString("Explanation: " + suggestion.Explanation + "\n")
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -2,16 +2,35 @@ package llm
 
 type MockClient struct {
 	suggestions []CommitSuggestion
-	err        error
+	groups      []CommitGroup
+	err         error
 }
 
 func NewMockClient(suggestions []CommitSuggestion, err error) *MockClient {
 	return &MockClient{
 		suggestions: suggestions,
-		err:        err,
+		err:         err,
 	}
 }
 
 func (m *MockClient) GenerateCommitSuggestions(changes string) ([]CommitSuggestion, error) {
 	return m.suggestions, m.err
-} 
\ No newline at end of file
+}
+
+// SetGroups sets the groups returned by GenerateCommitGroups
+func (m *MockClient) SetGroups(groups []CommitGroup) {
+	m.groups = groups
+}
+
+func (m *MockClient) GenerateCommitGroups(units string) ([]CommitGroup, error) {
+	return m.groups, m.err
+}
+
+// Chat replies with the configured suggestions in the prompt's format
+func (m *MockClient) Chat(turns []Turn) (string, error) {
+	return formatSuggestions(m.suggestions), m.err
+}
+
+func (m *MockClient) Usage() Usage {
+	return Usage{}
+}
@@ -14,6 +14,7 @@ import (
 type OllamaClient struct {
 	baseURL string
 	model   string
+	usage   Usage
 }
 
 type ollamaRequest struct {
@@ -22,8 +23,27 @@ type ollamaRequest struct {
 	Stream bool   `json:"stream"`
 }
 
+type ollamaChatRequest struct {
+	Model    string              `json:"model"`
+	Messages []ollamaChatMessage `json:"messages"`
+	Stream   bool                `json:"stream"`
+}
+
+type ollamaChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaChatResponse struct {
+	Message         ollamaChatMessage `json:"message"`
+	PromptEvalCount int64             `json:"prompt_eval_count"`
+	EvalCount       int64             `json:"eval_count"`
+}
+
 type ollamaResponse struct {
-	Response string `json:"response"`
+	Response        string `json:"response"`
+	PromptEvalCount int64  `json:"prompt_eval_count"`
+	EvalCount       int64  `json:"eval_count"`
 }
 
 func NewOllamaClient() (*OllamaClient, error) {
@@ -48,6 +68,74 @@ func (c *OllamaClient) GenerateCommitSuggestions(changes string) ([]CommitSugges
 	logger.Debugf("Input changes to generate suggestions: %s", changes)
 
 	prompt := buildPrompt(changes)
+
+	response, err := c.complete(prompt)
+	if err != nil {
+		return nil, err
+	}
+	return parseResponse(response), nil
+}
+
+func (c *OllamaClient) GenerateCommitGroups(units string) ([]CommitGroup, error) {
+	if units == "" {
+		return nil, fmt.Errorf("no changes provided to split")
+	}
+
+	response, err := c.complete(buildSplitPrompt(units))
+	if err != nil {
+		return nil, err
+	}
+	return parseGroups(response), nil
+}
+
+func (c *OllamaClient) Usage() Usage {
+	return c.usage
+}
+
+// Chat sends the conversation to the chat endpoint and returns the reply
+func (c *OllamaClient) Chat(turns []Turn) (string, error) {
+	reqBody := ollamaChatRequest{Model: c.model, Stream: false}
+	for _, turn := range turns {
+		reqBody.Messages = append(reqBody.Messages, ollamaChatMessage{Role: turn.Role, Content: turn.Content})
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	logger.Debugf("Sending chat request to Ollama URL: %s", c.baseURL+"/api/chat")
+	logger.Debugf("Request payload: %s", string(jsonData))
+
+	resp, err := http.Post(c.baseURL+"/api/chat", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		logger.Errorf("Failed to send request to Ollama: %v", err)
+		return "", fmt.Errorf("failed to send request to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	rawBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	logger.Debugf("Raw Ollama response: %s", string(rawBody))
+
+	var chatResp ollamaChatResponse
+	if err := json.Unmarshal(rawBody, &chatResp); err != nil {
+		logger.Errorf("Failed to decode response: %v", err)
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	c.usage.InputTokens += chatResp.PromptEvalCount
+	c.usage.OutputTokens += chatResp.EvalCount
+
+	if chatResp.Message.Content == "" {
+		logger.Errorf("Received empty response from Ollama")
+		return "", fmt.Errorf("empty response from Ollama")
+	}
+	return chatResp.Message.Content, nil
+}
+
+// complete sends a prompt to the generate endpoint and returns the reply
+func (c *OllamaClient) complete(prompt string) (string, error) {
 	logger.Debugf("Generated prompt: %s", prompt)
 
 	reqBody := ollamaRequest{
@@ -59,7 +147,7 @@ func (c *OllamaClient) GenerateCommitSuggestions(changes string) ([]CommitSugges
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		logger.Errorf("Failed to marshal request: %v", err)
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	logger.Debugf("Sending request to Ollama URL: %s", c.baseURL+"/api/generate")
@@ -68,7 +156,7 @@ func (c *OllamaClient) GenerateCommitSuggestions(changes string) ([]CommitSugges
 	resp, err := http.Post(c.baseURL+"/api/generate", "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		logger.Errorf("Failed to send request to Ollama: %v", err)
-		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
+		return "", fmt.Errorf("failed to send request to Ollama: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -77,22 +165,25 @@ func (c *OllamaClient) GenerateCommitSuggestions(changes string) ([]CommitSugges
 	// Add debug logging for raw response
 	rawBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 	logger.Debugf("Raw Ollama response: %s", string(rawBody))
 
 	var ollamaResp ollamaResponse
 	if err := json.Unmarshal(rawBody, &ollamaResp); err != nil {
 		logger.Errorf("Failed to decode response: %v", err)
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	c.usage.InputTokens += ollamaResp.PromptEvalCount
+	c.usage.OutputTokens += ollamaResp.EvalCount
+
 	if ollamaResp.Response == "" {
 		logger.Errorf("Received empty response from Ollama")
-		return nil, fmt.Errorf("empty response from Ollama")
+		return "", fmt.Errorf("empty response from Ollama")
 	}
 
 	logger.Debugf("\n=== Response from Ollama ===\n%s\n", ollamaResp.Response)
 
-	return parseResponse(ollamaResp.Response), nil
+	return ollamaResp.Response, nil
 }
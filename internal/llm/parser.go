@@ -1,6 +1,8 @@
 package llm
 
 import (
+	"regexp"
+	"strconv"
 	"strings"
 	"github.com/ozankasikci/gitai/internal/logger"
 )
@@ -68,4 +70,42 @@ func parseResponse(response string) []CommitSuggestion {
 	}
 	
 	return suggestions
-} 
\ No newline at end of file
+}
+
+// unitIDPattern matches the unit numbers listed after "Units:"
+var unitIDPattern = regexp.MustCompile(`\d+`)
+
+// parseGroups parses commit groups in the format requested by
+// buildSplitPrompt. Message and explanation lines are parsed like
+// suggestions; a "Units:" line lists the group's unit IDs.
+func parseGroups(response string) []CommitGroup {
+	logger.Debugf("\n=== Starting to parse groups ===\nFull response text:\n%s\n", response)
+
+	var groups []CommitGroup
+	var current *CommitGroup
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+
+		switch {
+		case current != nil && strings.HasPrefix(lower, "units:"):
+			for _, id := range unitIDPattern.FindAllString(line, -1) {
+				if n, err := strconv.Atoi(id); err == nil {
+					current.Units = append(current.Units, n)
+				}
+			}
+		case current != nil && strings.HasPrefix(lower, "explanation:"):
+			current.Explanation = strings.TrimSpace(line[len("explanation:"):])
+		case len(line) > 2 && line[0] >= '1' && line[0] <= '9':
+			suggestions := parseResponse(line)
+			if len(suggestions) == 0 || suggestions[0].Message == "" {
+				continue
+			}
+			groups = append(groups, CommitGroup{Message: suggestions[0].Message})
+			current = &groups[len(groups)-1]
+		}
+	}
+
+	logger.Debugf("Found %d commit groups", len(groups))
+	return groups
+}
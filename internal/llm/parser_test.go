@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []CommitGroup
+	}{
+		{
+			name: "groups with explanations and units",
+			response: "1 - refactor: extract config loading\n" +
+				"Explanation: Moves config parsing out of main\n" +
+				"Units: 1, 2, 5\n\n" +
+				"2 - feat: retry requests\n" +
+				"explanation: Retries with backoff\n" +
+				"units: 3 and 4\n",
+			want: []CommitGroup{
+				{Message: "refactor: extract config loading", Explanation: "Moves config parsing out of main", Units: []int{1, 2, 5}},
+				{Message: "feat: retry requests", Explanation: "Retries with backoff", Units: []int{3, 4}},
+			},
+		},
+		{
+			name:     "units before any group are ignored",
+			response: "Units: 1\n1 - fix: handle empty input\nUnits: 2\n",
+			want:     []CommitGroup{{Message: "fix: handle empty input", Units: []int{2}}},
+		},
+		{
+			name:     "group without units",
+			response: "1 - docs: update readme\n",
+			want:     []CommitGroup{{Message: "docs: update readme"}},
+		},
+		{
+			name:     "no groups",
+			response: "I could not group these changes.",
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseGroups(tt.response), tt.name)
+	}
+}
@@ -22,6 +22,9 @@ When multiple files are changed:
 - Identify the primary purpose of the changes
 - Consider if changes are related (e.g., refactoring across files)
 
+If a current commit message is included, use it as a hint about the intent of
+the changes, but write better messages rather than repeating it.
+
 Analyze the following git diff and generate 3 different commit messages.
 
 Format each suggestion exactly like this example:
@@ -51,4 +54,68 @@ Changes:
 
 Remember to format each suggestion exactly like the example above.
 `, changes)
-} 
\ No newline at end of file
+}
+
+// regeneratePrompt asks for a fresh set of suggestions in a conversation
+const regeneratePrompt = `Generate 3 new commit messages for the same changes, different from the ones above.
+
+Remember to format each suggestion exactly like the example above.`
+
+// morePrompt asks for additional suggestions in a conversation
+const morePrompt = `Generate 3 more commit messages for the same changes, different from all of the ones above.
+
+Remember to format each suggestion exactly like the example above.`
+
+// buildFeedbackPrompt asks for suggestions revised according to the user's
+// feedback on the ones above
+func buildFeedbackPrompt(feedback string) string {
+	return fmt.Sprintf(`Revise the commit messages based on this feedback:
+%s
+
+Generate 3 revised commit messages for the same changes, following the feedback and the rules above.
+
+Remember to format each suggestion exactly like the example above.`, feedback)
+}
+
+func buildSplitPrompt(units string) string {
+	return fmt.Sprintf(`
+You are a highly intelligent assistant skilled in understanding code changes. I will provide you with code changes broken into numbered units. Each unit is either a whole file or one hunk of a modified file.
+
+Your task is to group the units into a small number of coherent, logical commits that a careful developer would have made separately, for example a refactoring, a bug fix and a new feature. Units that belong to the same change must be in the same commit, even when they are in different files.
+
+Rules for grouping:
+- Every unit must be assigned to exactly one commit
+- Order the commits so that each one builds on the previous ones
+- Do not create a commit for a single unit unless it is unrelated to everything else
+- If all changes belong together, answer with a single commit
+
+Format each commit exactly like this example:
+1 - Extract config loading into its own package
+Explanation: Moves config parsing out of main without changing behavior
+Units: 1, 2, 5
+
+2 - Add retry to API client
+Explanation: Retries failed requests with backoff
+Units: 3, 4
+
+Follow these git commit message rules:
+1. Use imperative mood ("Add" not "Added" or "Adds")
+2. First line should be 50 chars or less
+3. First line should be capitalized
+4. No period at the end of the first line
+
+Optionally, you can use these Conventional Commits prefixes if appropriate:
+- feat: new feature
+- fix: bug fix
+- docs: documentation only
+- style: formatting
+- refactor: code change that neither fixes a bug nor adds a feature
+- test: adding missing tests
+- chore: maintain
+
+Units:
+%s
+
+Remember to format each commit exactly like the example above and to list every unit.
+`, units)
+}
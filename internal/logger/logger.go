@@ -37,6 +37,15 @@ func UpdateConfig(verbose bool) {
 	}
 }
 
+// UseStderr sends info and debug output to stderr, leaving stdout to
+// output meant for other programs
+func UseStderr() {
+	Info.SetOutput(os.Stderr)
+	if Verbose {
+		Debug.SetOutput(os.Stderr)
+	}
+}
+
 func Infof(format string, v ...interface{}) {
 	Info.Printf(format, v...)
 }
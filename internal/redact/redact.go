@@ -0,0 +1,179 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// minEntropyLength is the shortest token considered by the entropy detector
+const minEntropyLength = 32
+
+// entropyThreshold is the Shannon entropy (bits per character) above which a
+// token is treated as a secret. Hex strings such as commit hashes top out at 4.
+const entropyThreshold = 4.5
+
+// detector finds one kind of secret
+type detector struct {
+	name    string
+	pattern *regexp.Regexp
+	// validate, when set, filters matches found by pattern
+	validate func(text string, loc []int) bool
+}
+
+var builtinDetectors = []detector{
+	{
+		name:    "private-key",
+		pattern: regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----.*?(-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----|\z)`),
+	},
+	{
+		name:    "aws-access-key-id",
+		pattern: regexp.MustCompile(`\b(AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	},
+	{
+		name:    "aws-secret-access-key",
+		pattern: regexp.MustCompile(`(?i)aws_?secret_?(access_?)?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`),
+	},
+	{
+		name:    "jwt",
+		pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`),
+	},
+	{
+		name:     "high-entropy-string",
+		pattern:  regexp.MustCompile(`[A-Za-z0-9+/_-]{32,}={0,2}`),
+		validate: isHighEntropySecret,
+	},
+}
+
+// Finding records the secrets redacted from a single file
+type Finding struct {
+	Path      string
+	Detectors []string
+}
+
+// Redactor replaces secrets with placeholders and remembers which files
+// contained them
+type Redactor struct {
+	detectors []detector
+	findings  map[string]map[string]bool
+}
+
+// New creates a redactor with the built-in detectors plus the given
+// user-defined regular expressions
+func New(patterns []string) (*Redactor, error) {
+	detectors := append([]detector{}, builtinDetectors...)
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		detectors = append(detectors, detector{name: "custom", pattern: re})
+	}
+
+	return &Redactor{
+		detectors: detectors,
+		findings:  make(map[string]map[string]bool),
+	}, nil
+}
+
+// Redact replaces every secret in text with a "[REDACTED:<kind>]" placeholder
+// and records the finding under path
+func (r *Redactor) Redact(path, text string) string {
+	for _, d := range r.detectors {
+		matched := false
+		text = replaceMatches(d, text, func() { matched = true })
+		if matched {
+			if r.findings[path] == nil {
+				r.findings[path] = make(map[string]bool)
+			}
+			r.findings[path][d.name] = true
+		}
+	}
+	return text
+}
+
+// Findings returns the files that contained secrets, sorted by path
+func (r *Redactor) Findings() []Finding {
+	findings := make([]Finding, 0, len(r.findings))
+	for path, names := range r.findings {
+		finding := Finding{Path: path}
+		for name := range names {
+			finding.Detectors = append(finding.Detectors, name)
+		}
+		sort.Strings(finding.Detectors)
+		findings = append(findings, finding)
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Path < findings[j].Path
+	})
+	return findings
+}
+
+func replaceMatches(d detector, text string, onMatch func()) string {
+	locs := d.pattern.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return text
+	}
+
+	placeholder := fmt.Sprintf("[REDACTED:%s]", d.name)
+	var builder strings.Builder
+	last := 0
+	for _, loc := range locs {
+		if d.validate != nil && !d.validate(text, loc) {
+			continue
+		}
+		onMatch()
+		builder.WriteString(text[last:loc[0]])
+		builder.WriteString(placeholder)
+		last = loc[1]
+	}
+	builder.WriteString(text[last:])
+
+	return builder.String()
+}
+
+// checksumPrefixes mark integrity hashes in lockfiles and go.sum, which look
+// random but are not secrets
+var checksumPrefixes = []string{"h1:", "sha1-", "sha256-", "sha384-", "sha512-"}
+
+func isHighEntropySecret(text string, loc []int) bool {
+	token := text[loc[0]:loc[1]]
+	if len(token) < minEntropyLength {
+		return false
+	}
+
+	prefix := text[max(0, loc[0]-len("sha512-")):loc[0]]
+	for _, checksum := range checksumPrefixes {
+		if strings.HasSuffix(prefix, checksum) {
+			return false
+		}
+	}
+
+	// Paths and identifiers made of a single character class are not secrets
+	hasUpper := strings.ContainsAny(token, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	hasLower := strings.ContainsAny(token, "abcdefghijklmnopqrstuvwxyz")
+	hasDigit := strings.ContainsAny(token, "0123456789")
+	if !(hasUpper && hasLower && hasDigit) {
+		return false
+	}
+
+	return shannonEntropy(token) > entropyThreshold
+}
+
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	var entropy float64
+	length := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}